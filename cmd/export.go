@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/migalabs/eth-light-crawler/pkg/config"
+	"github.com/migalabs/eth-light-crawler/pkg/crawler"
+	"github.com/migalabs/eth-light-crawler/pkg/db"
+	"github.com/migalabs/eth-light-crawler/pkg/export"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	cli "github.com/urfave/cli/v2"
+)
+
+var Export = &cli.Command{
+	Name:   "export",
+	Usage:  "export the crawled ENRs to a CSV, NDJSON or Parquet file",
+	Action: RunExport,
+	Flags: []cli.Flag{
+		dbEndpointFlag,
+		&cli.StringFlag{
+			Name:  "output",
+			Usage: "file where the ENRs are exported (stdout if empty)",
+		},
+		&cli.StringFlag{
+			Name:  "format",
+			Usage: "export format [csv,ndjson,parquet] (guessed from the output extension if not given)",
+		},
+		&cli.StringSliceFlag{
+			Name:  "fork-digest",
+			Usage: "only export the nodes advertising the given fork digest (can be repeated)",
+		},
+		&cli.TimestampFlag{
+			Name:   "since",
+			Usage:  "only export the nodes last seen after the given time (RFC3339)",
+			Layout: time.RFC3339,
+		},
+		&cli.TimestampFlag{
+			Name:   "until",
+			Usage:  "only export the nodes last seen before the given time (RFC3339)",
+			Layout: time.RFC3339,
+		},
+		&cli.BoolFlag{
+			Name:  "anonymize-ips",
+			Usage: "zero the host part of the IPs (/24 for IPv4, /48 for IPv6) and leave out the raw ENRs",
+			Value: false,
+		},
+		&cli.DurationFlag{
+			Name:  "live",
+			Usage: "export the nodes of a live crawl of the given duration instead of the ones of the db",
+		},
+		&cli.StringFlag{
+			Name:  "network",
+			Usage: "network whose built-in bootnodes will be used by the live crawl [mainnet,goerli,sepolia,holesky,gnosis]",
+			Value: config.MainnetNetwork,
+		},
+		&cli.IntFlag{
+			Name:  "port",
+			Usage: "port number used by the live crawl",
+			Value: config.DefaultConfig.UDP,
+		},
+	},
+}
+
+func RunExport(ctx *cli.Context) error {
+	conf := config.DefaultConfig
+	conf.Apply(ctx)
+
+	format := ctx.String("format")
+	if format == "" {
+		format = formatFromPath(ctx.String("output"))
+	}
+
+	forkDigests, err := conf.GetForkDigests()
+	if err != nil {
+		return err
+	}
+	filter := &db.EnrFilter{ForkDigests: forkDigests}
+	if since := ctx.Timestamp("since"); since != nil {
+		filter.Since = *since
+	}
+	if until := ctx.Timestamp("until"); until != nil {
+		filter.Until = *until
+	}
+
+	var store db.Store
+	if live := ctx.Duration("live"); live > 0 {
+		// crawl into a memory store, and export its nodes once the crawl is done
+		// (the --output of the export isn't the output file of the crawler)
+		conf.DBEndpoint = db.MemoryPrefix
+		conf.Output = ""
+		conf.CrawlDuration = live
+		crawlr, err := crawler.New(ctx.Context, &conf)
+		if err != nil {
+			return err
+		}
+		log.Infof("running a live crawl of %s to export its nodes", live)
+		if err := crawlr.Run(live); err != nil {
+			return err
+		}
+		store = crawlr.Store()
+	} else {
+		store, err = db.NewReadOnlyStore(ctx.Context, ctx.String("db-endpoint"), conf.DBPersisters, conf.DBBatchSize, conf.DBFlushInterval)
+		if err != nil {
+			return err
+		}
+		defer store.Close()
+	}
+
+	output := os.Stdout
+	if path := ctx.String("output"); path != "" {
+		output, err = os.Create(path)
+		if err != nil {
+			return errors.Wrap(err, "unable to create the output file")
+		}
+		defer output.Close()
+	}
+	writer, err := export.NewWriter(format, output)
+	if err != nil {
+		return err
+	}
+
+	exported, err := export.Export(store, filter, writer, ctx.Bool("anonymize-ips"))
+	if err != nil {
+		return err
+	}
+	log.Infof("exported %d nodes (%s)", exported, format)
+	return nil
+}
+
+// formatFromPath guesses the export format from the extension of the output file (ndjson by default)
+func formatFromPath(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return export.CSVFormat
+	case ".parquet":
+		return export.ParquetFormat
+	default:
+		return export.NDJSONFormat
+	}
+}
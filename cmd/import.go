@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"io"
+	"os"
+
+	"github.com/migalabs/eth-light-crawler/pkg/config"
+	"github.com/migalabs/eth-light-crawler/pkg/crawler"
+	"github.com/migalabs/eth-light-crawler/pkg/db"
+	"github.com/migalabs/eth-light-crawler/pkg/importer"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	cli "github.com/urfave/cli/v2"
+)
+
+var Import = &cli.Command{
+	Name:      "import",
+	Usage:     "import ENRs collected from other sources (one per line, or JSON) into the database",
+	ArgsUsage: "[files...] (stdin if none)",
+	Action:    RunImport,
+	Flags: []cli.Flag{
+		dbEndpointFlag,
+		&cli.StringFlag{
+			Name:  "source",
+			Usage: "tag of the imported ENRs in the source column of the enrs table",
+			Value: "import",
+		},
+		&cli.DurationFlag{
+			Name:  "crawl",
+			Usage: "run a crawl of the given duration seeded with the imported ENRs (0 = no crawl)",
+		},
+		&cli.StringFlag{
+			Name:  "mode",
+			Usage: "crawl mode of the seeded crawl [random,targeted]",
+			Value: config.TargetedCrawlMode,
+		},
+		&cli.StringFlag{
+			Name:  "network",
+			Usage: "network whose built-in bootnodes are added to the seeds of the crawl [mainnet,goerli,sepolia,holesky,gnosis]",
+			Value: config.MainnetNetwork,
+		},
+		&cli.IntFlag{
+			Name:  "port",
+			Usage: "port number used by the seeded crawl",
+			Value: config.DefaultConfig.UDP,
+		},
+	},
+}
+
+func RunImport(ctx *cli.Context) error {
+	records, err := readImportRecords(ctx.Args().Slice())
+	if err != nil {
+		return err
+	}
+	log.Infof("read %d records to import", len(records))
+
+	conf := config.DefaultConfig
+	conf.Apply(ctx)
+	conf.DBEndpoint = ctx.String("db-endpoint")
+	conf.CrawlMode = ctx.String("mode")
+
+	store, err := db.NewStore(ctx.Context, conf.DBEndpoint, false, conf.DBPersisters, conf.DBBatchSize, conf.DBFlushInterval)
+	if err != nil {
+		return err
+	}
+	enrs, stats, err := importer.Import(store, records, ctx.String("source"))
+	// flush the imported ENRs before the crawl opens the db again
+	store.Close()
+	if err != nil {
+		return err
+	}
+	log.WithFields(log.Fields{
+		"imported":   stats.Imported,
+		"duplicated": stats.Duplicated,
+		"invalid":    stats.Invalid,
+		"source":     ctx.String("source"),
+	}).Info("import finished")
+
+	duration := ctx.Duration("crawl")
+	if duration <= 0 || len(enrs) == 0 {
+		return nil
+	}
+
+	// seed the crawl with the imported nodes on top of the bootnodes of the network
+	bootnodes, err := config.GetNetworkBootnodes(conf.Network)
+	if err != nil {
+		return err
+	}
+	for _, bootnode := range bootnodes {
+		conf.Bootnodes = append(conf.Bootnodes, bootnode.String())
+	}
+	for _, enr := range enrs {
+		conf.Bootnodes = append(conf.Bootnodes, enr.Enr)
+	}
+	crawlr, err := crawler.New(ctx.Context, &conf)
+	if err != nil {
+		return err
+	}
+	log.Infof("running a crawl of %s seeded with %d imported nodes", duration, len(enrs))
+	return crawlr.Run(duration)
+}
+
+// readImportRecords reads the records of the given files, or of stdin if there are none
+func readImportRecords(paths []string) ([]string, error) {
+	if len(paths) == 0 {
+		paths = []string{"-"}
+	}
+	records := make([]string, 0)
+	for _, path := range paths {
+		fileRecords, err := readImportFile(path)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, fileRecords...)
+	}
+	return records, nil
+}
+
+func readImportFile(path string) ([]string, error) {
+	var input io.Reader = os.Stdin
+	if path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to open the import file")
+		}
+		defer file.Close()
+		input = file
+	}
+	records, err := importer.ReadRecords(input)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to read %s", path)
+	}
+	return records, nil
+}
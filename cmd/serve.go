@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"github.com/migalabs/eth-light-crawler/pkg/api"
+	"github.com/migalabs/eth-light-crawler/pkg/config"
+	"github.com/migalabs/eth-light-crawler/pkg/db"
+
+	cli "github.com/urfave/cli/v2"
+)
+
+var apiAddrFlag = &cli.StringFlag{
+	Name:  "api-addr",
+	Usage: "address (host:port) where the HTTP JSON API is served",
+	Value: "127.0.0.1:8080",
+}
+
+var Serve = &cli.Command{
+	Name:   "serve",
+	Usage:  "serve the crawled nodes of the database through an HTTP JSON API",
+	Action: RunServe,
+	Flags: []cli.Flag{
+		dbEndpointFlag,
+		apiAddrFlag,
+	},
+}
+
+func RunServe(ctx *cli.Context) error {
+	conf := config.DefaultConfig
+	store, err := db.NewReadOnlyStore(ctx.Context, ctx.String("db-endpoint"), conf.DBPersisters, conf.DBBatchSize, conf.DBFlushInterval)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	return api.NewServer(store, ctx.String("api-addr")).Serve(ctx.Context)
+}
@@ -17,7 +17,8 @@ var (
 )
 
 func main() {
-	fmt.Println(CliName, CliVersion)
+	// the banner goes to stderr, so that stdout only carries the output of the commands (i.e. export)
+	fmt.Fprintln(os.Stderr, CliName, CliVersion)
 
 	lightCrawler := cli.App{
 		Name:      CliName,
@@ -25,6 +26,10 @@ func main() {
 		UsageText: "eth-light-crawler [subcommands] [arguments]",
 		Commands: []*cli.Command{
 			cmd.Discovery5,
+			cmd.Migrate,
+			cmd.Serve,
+			cmd.Export,
+			cmd.Import,
 		},
 	}
 
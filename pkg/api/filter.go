@@ -0,0 +1,123 @@
+package api
+
+import (
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/migalabs/eth-light-crawler/pkg/db"
+	"github.com/pkg/errors"
+	"github.com/protolambda/zrnt/eth2/beacon/common"
+)
+
+// number of nodes returned by /nodes if no limit is given
+const defaultNodesLimit = 100
+
+// parseFilter composes the filter of the nodes from the query params:
+//   - fork_digest: fork digests of the nodes (comma-separated or repeated)
+//   - ip: IP address or CIDR network of the nodes (IPv4 or IPv6)
+//   - attnets, min_attnets, max_attnets: number of attnets that the nodes are subscribed to
+//   - since, until: time window (RFC3339) in which the nodes were last seen
+//   - limit: max number of returned nodes (0 = all of them), only if withLimit is set
+func parseFilter(query url.Values, withLimit bool) (*db.EnrFilter, error) {
+	filter := new(db.EnrFilter)
+
+	for _, param := range query["fork_digest"] {
+		for _, rawDigest := range strings.Split(param, ",") {
+			var forkDigest common.ForkDigest
+			if err := forkDigest.UnmarshalText([]byte(strings.TrimSpace(rawDigest))); err != nil {
+				return nil, errors.Wrap(err, "invalid fork_digest "+rawDigest)
+			}
+			filter.ForkDigests = append(filter.ForkDigests, forkDigest)
+		}
+	}
+
+	if rawIP := query.Get("ip"); rawIP != "" {
+		ipNet, err := parseIPNet(rawIP)
+		if err != nil {
+			return nil, err
+		}
+		filter.IPNet = ipNet
+	}
+
+	var err error
+	if filter.MinAttnets, err = intParam(query, "min_attnets"); err != nil {
+		return nil, err
+	}
+	if filter.MaxAttnets, err = intParam(query, "max_attnets"); err != nil {
+		return nil, err
+	}
+	attnets, err := intParam(query, "attnets")
+	if err != nil {
+		return nil, err
+	}
+	if attnets != nil {
+		filter.MinAttnets, filter.MaxAttnets = attnets, attnets
+	}
+
+	if filter.Since, err = timeParam(query, "since"); err != nil {
+		return nil, err
+	}
+	if filter.Until, err = timeParam(query, "until"); err != nil {
+		return nil, err
+	}
+
+	if withLimit {
+		filter.Limit = defaultNodesLimit
+		limit, err := intParam(query, "limit")
+		if err != nil {
+			return nil, err
+		}
+		if limit != nil {
+			filter.Limit = *limit
+		}
+	}
+	return filter, nil
+}
+
+// parseIPNet parses a CIDR network, or a single IP as the network that only contains it
+func parseIPNet(raw string) (*net.IPNet, error) {
+	if strings.Contains(raw, "/") {
+		_, ipNet, err := net.ParseCIDR(raw)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid ip network "+raw)
+		}
+		return ipNet, nil
+	}
+	ip := net.ParseIP(raw)
+	if ip == nil {
+		return nil, errors.New("invalid ip " + raw)
+	}
+	if ip4 := ip.To4(); ip4 != nil {
+		return &net.IPNet{IP: ip4, Mask: net.CIDRMask(32, 32)}, nil
+	}
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)}, nil
+}
+
+// intParam returns the non-negative integer param, or nil if it wasn't given
+func intParam(query url.Values, name string) (*int, error) {
+	raw := query.Get(name)
+	if raw == "" {
+		return nil, nil
+	}
+	value, err := strconv.Atoi(raw)
+	if err != nil || value < 0 {
+		return nil, errors.Errorf("invalid %s %s, expected a non-negative integer", name, raw)
+	}
+	return &value, nil
+}
+
+// timeParam returns the RFC3339 time param, or the zero time if it wasn't given
+func timeParam(query url.Values, name string) (time.Time, error) {
+	raw := query.Get(name)
+	if raw == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return time.Time{}, errors.Errorf("invalid %s %s, expected an RFC3339 time", name, raw)
+	}
+	return t, nil
+}
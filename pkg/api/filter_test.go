@@ -0,0 +1,155 @@
+package api
+
+import (
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestParseFilter(t *testing.T) {
+	tests := []struct {
+		name        string
+		query       string
+		withLimit   bool
+		forkDigests int
+		ipNet       string
+		minAttnets  int
+		maxAttnets  int
+		since       time.Time
+		limit       int
+		valid       bool
+	}{
+		{
+			name:       "empty",
+			minAttnets: -1,
+			maxAttnets: -1,
+			valid:      true,
+		},
+		{
+			name:       "default limit",
+			withLimit:  true,
+			minAttnets: -1,
+			maxAttnets: -1,
+			limit:      defaultNodesLimit,
+			valid:      true,
+		},
+		{
+			name:       "limit only with withLimit",
+			query:      "limit=5",
+			minAttnets: -1,
+			maxAttnets: -1,
+			valid:      true,
+		},
+		{
+			name:       "no limit",
+			query:      "limit=0",
+			withLimit:  true,
+			minAttnets: -1,
+			maxAttnets: -1,
+			limit:      0,
+			valid:      true,
+		},
+		{
+			name:        "comma-separated and repeated fork digests",
+			query:       "fork_digest=0xb5303f2a,0xafcaaba0&fork_digest=0xbba4da96",
+			forkDigests: 3,
+			minAttnets:  -1,
+			maxAttnets:  -1,
+			valid:       true,
+		},
+		{
+			name:       "single IPv4",
+			query:      "ip=10.0.0.1",
+			ipNet:      "10.0.0.1/32",
+			minAttnets: -1,
+			maxAttnets: -1,
+			valid:      true,
+		},
+		{
+			name:       "IPv6 network",
+			query:      "ip=2001:db8::/32",
+			ipNet:      "2001:db8::/32",
+			minAttnets: -1,
+			maxAttnets: -1,
+			valid:      true,
+		},
+		{
+			name:       "attnets range",
+			query:      "min_attnets=2&max_attnets=10",
+			minAttnets: 2,
+			maxAttnets: 10,
+			valid:      true,
+		},
+		{
+			name:       "exact attnets override the range",
+			query:      "min_attnets=2&max_attnets=10&attnets=64",
+			minAttnets: 64,
+			maxAttnets: 64,
+			valid:      true,
+		},
+		{
+			name:       "since",
+			query:      "since=2023-05-01T12:00:00Z",
+			minAttnets: -1,
+			maxAttnets: -1,
+			since:      time.Date(2023, 5, 1, 12, 0, 0, 0, time.UTC),
+			valid:      true,
+		},
+		{name: "invalid fork digest", query: "fork_digest=0xb530"},
+		{name: "invalid ip", query: "ip=10.0.0"},
+		{name: "invalid network", query: "ip=10.0.0.0/33"},
+		{name: "negative attnets", query: "min_attnets=-1"},
+		{name: "invalid limit", query: "limit=all", withLimit: true},
+		{name: "invalid time", query: "until=yesterday"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			query, err := url.ParseQuery(test.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			filter, err := parseFilter(query, test.withLimit)
+			if !test.valid {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if len(filter.ForkDigests) != test.forkDigests {
+				t.Errorf("%d fork digests, expected %d", len(filter.ForkDigests), test.forkDigests)
+			}
+			ipNet := ""
+			if filter.IPNet != nil {
+				ipNet = filter.IPNet.String()
+			}
+			if ipNet != test.ipNet {
+				t.Errorf("ip network %q, expected %q", ipNet, test.ipNet)
+			}
+			if value := optionalInt(filter.MinAttnets); value != test.minAttnets {
+				t.Errorf("min attnets %d, expected %d", value, test.minAttnets)
+			}
+			if value := optionalInt(filter.MaxAttnets); value != test.maxAttnets {
+				t.Errorf("max attnets %d, expected %d", value, test.maxAttnets)
+			}
+			if !filter.Since.Equal(test.since) {
+				t.Errorf("since %s, expected %s", filter.Since, test.since)
+			}
+			if filter.Limit != test.limit {
+				t.Errorf("limit %d, expected %d", filter.Limit, test.limit)
+			}
+		})
+	}
+}
+
+// optionalInt returns the value of the optional int (-1 if not set)
+func optionalInt(value *int) int {
+	if value == nil {
+		return -1
+	}
+	return *value
+}
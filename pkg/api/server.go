@@ -0,0 +1,162 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/migalabs/eth-light-crawler/pkg/db"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+const shutdownTimeout = 5 * time.Second
+
+// Server exposes the nodes of the store through a read-only HTTP JSON API
+type Server struct {
+	store  db.Store
+	addr   string
+	server *http.Server
+}
+
+func NewServer(store db.Store, addr string) *Server {
+	s := &Server{
+		store: store,
+		addr:  addr,
+	}
+	s.server = &http.Server{
+		Addr:              addr,
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: shutdownTimeout,
+	}
+	return s
+}
+
+// Serve listens at the address of the server until the context is done or the server is closed
+func (s *Server) Serve(ctx context.Context) error {
+	stopC := make(chan struct{})
+	defer close(stopC)
+	go func() {
+		select {
+		case <-ctx.Done():
+			s.Close()
+		case <-stopC:
+		}
+	}()
+
+	log.Infof("serving the api at http://%s", s.addr)
+	err := s.server.ListenAndServe()
+	if err != nil && err != http.ErrServerClosed {
+		return errors.Wrap(err, "unable to serve the api")
+	}
+	return nil
+}
+
+// Close stops accepting requests and waits (up to the shutdown timeout) for the ongoing ones,
+// so that the store can be closed right after
+func (s *Server) Close() {
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := s.server.Shutdown(shutdownCtx); err != nil {
+		log.Error(errors.Wrap(err, "unable to shut down the api"))
+	}
+}
+
+// Handler routes the endpoints of the API
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /nodes", s.handleNodes)
+	mux.HandleFunc("GET /nodes/{id}", s.handleNode)
+	mux.HandleFunc("GET /stats/forks", s.handleForkStats)
+	mux.HandleFunc("GET /stats/subnets", s.handleSubnetStats)
+	return mux
+}
+
+// handleNodes lists the nodes that match the filter of the query, the most recently seen first
+func (s *Server) handleNodes(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseFilter(r.URL.Query(), true)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	enrs, err := s.store.GetEnrs(filter)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	nodes := make([]*nodeResponse, 0, len(enrs))
+	for _, enr := range enrs {
+		nodes = append(nodes, newNodeResponse(enr))
+	}
+	writeJSON(w, http.StatusOK, &nodesResponse{
+		Count: len(nodes),
+		Nodes: nodes,
+	})
+}
+
+// handleNode returns the node with the given node_id
+func (s *Server) handleNode(w http.ResponseWriter, r *http.Request) {
+	id, err := enode.ParseID(r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, errors.Wrap(err, "invalid node id"))
+		return
+	}
+	enr, err := s.store.GetEnr(id)
+	if err == db.ErrNotFound {
+		writeError(w, http.StatusNotFound, errors.Errorf("node %s not found", id))
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, newNodeResponse(enr))
+}
+
+// handleForkStats counts the nodes that match the filter of the query by fork digest
+func (s *Server) handleForkStats(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseFilter(r.URL.Query(), false)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	stats, err := s.store.GetForkStats(filter)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, newForkStatsResponse(stats))
+}
+
+// handleSubnetStats counts the nodes that match the filter of the query by subnet
+func (s *Server) handleSubnetStats(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseFilter(r.URL.Query(), false)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	stats, err := s.store.GetSubnetStats(filter)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, newSubnetStatsResponse(stats))
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		log.Warnf("unable to write the api response - %s", err.Error())
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	if status == http.StatusInternalServerError {
+		log.Error(err)
+	}
+	writeJSON(w, status, &errorResponse{Error: err.Error()})
+}
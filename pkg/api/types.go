@@ -0,0 +1,120 @@
+package api
+
+import (
+	"encoding/hex"
+	"net"
+	"time"
+
+	gcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/migalabs/eth-light-crawler/pkg/db"
+	"github.com/migalabs/eth-light-crawler/pkg/discv5"
+)
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+type nodesResponse struct {
+	Count int             `json:"count"`
+	Nodes []*nodeResponse `json:"nodes"`
+}
+
+// nodeResponse is the JSON representation of a stored ENR
+type nodeResponse struct {
+	NodeID          string    `json:"node_id"`
+	Seq             uint64    `json:"seq"`
+	Timestamp       time.Time `json:"timestamp"`
+	IP              string    `json:"ip,omitempty"`
+	TCP             int       `json:"tcp,omitempty"`
+	UDP             int       `json:"udp,omitempty"`
+	IP6             string    `json:"ip6,omitempty"`
+	TCP6            int       `json:"tcp6,omitempty"`
+	UDP6            int       `json:"udp6,omitempty"`
+	Pubkey          string    `json:"pubkey"`
+	ForkDigest      string    `json:"fork_digest"`
+	NextForkVersion string    `json:"next_fork_version"`
+	NextForkEpoch   uint64    `json:"next_fork_epoch"`
+	Attnets         string    `json:"attnets"`
+	AttnetsNumber   int       `json:"attnets_number"`
+	Syncnets        string    `json:"syncnets"`
+	SyncnetsNumber  int       `json:"syncnets_number"`
+	Enr             string    `json:"enr"`
+}
+
+func newNodeResponse(enr *discv5.EnrNode) *nodeResponse {
+	return &nodeResponse{
+		NodeID:          enr.ID.String(),
+		Seq:             enr.Seq,
+		Timestamp:       enr.Timestamp.UTC(),
+		IP:              ipString(enr.IP),
+		TCP:             enr.TCP,
+		UDP:             enr.UDP,
+		IP6:             ipString(enr.IP6),
+		TCP6:            enr.TCP6,
+		UDP6:            enr.UDP6,
+		Pubkey:          pubkeyString(enr),
+		ForkDigest:      enr.Eth2Data.ForkDigest.String(),
+		NextForkVersion: enr.Eth2Data.NextForkVersion.String(),
+		NextForkEpoch:   uint64(enr.Eth2Data.NextForkEpoch),
+		Attnets:         hex.EncodeToString(enr.Attnets.Raw[:]),
+		AttnetsNumber:   enr.Attnets.NetNumber,
+		Syncnets:        hex.EncodeToString(enr.Syncnets.Raw[:]),
+		SyncnetsNumber:  enr.Syncnets.NetNumber,
+		Enr:             enr.Enr,
+	}
+}
+
+// pubkeyString returns the hex encoded compressed public key of the node, if any
+func pubkeyString(enr *discv5.EnrNode) string {
+	if enr.Pubkey == nil || enr.Pubkey.X == nil {
+		return ""
+	}
+	return hex.EncodeToString(gcrypto.CompressPubkey(enr.Pubkey))
+}
+
+// ipString returns the empty string (omitted) for empty IPs, instead of their "<nil>" string
+func ipString(ip net.IP) string {
+	if len(ip) == 0 {
+		return ""
+	}
+	return ip.String()
+}
+
+type forkStatsResponse struct {
+	Nodes int              `json:"nodes"`
+	Forks []*forkNodesItem `json:"forks"`
+}
+
+type forkNodesItem struct {
+	ForkDigest string `json:"fork_digest"`
+	Nodes      int    `json:"nodes"`
+}
+
+func newForkStatsResponse(stats []db.ForkStats) *forkStatsResponse {
+	resp := &forkStatsResponse{
+		Forks: make([]*forkNodesItem, 0, len(stats)),
+	}
+	for _, fork := range stats {
+		resp.Nodes += fork.Nodes
+		resp.Forks = append(resp.Forks, &forkNodesItem{
+			ForkDigest: fork.ForkDigest.String(),
+			Nodes:      fork.Nodes,
+		})
+	}
+	return resp
+}
+
+// subnetStatsResponse holds the number of nodes subscribed to each subnet, indexed by subnet
+type subnetStatsResponse struct {
+	Nodes    int   `json:"nodes"`
+	Attnets  []int `json:"attnets"`
+	Syncnets []int `json:"syncnets"`
+}
+
+func newSubnetStatsResponse(stats *db.SubnetStats) *subnetStatsResponse {
+	return &subnetStatsResponse{
+		Nodes:    stats.Nodes,
+		Attnets:  stats.Attnets[:],
+		Syncnets: stats.Syncnets[:],
+	}
+}
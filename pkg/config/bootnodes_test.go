@@ -0,0 +1,92 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/migalabs/eth-light-crawler/pkg/discv5"
+	"github.com/protolambda/zrnt/eth2/beacon/common"
+)
+
+// fork versions of each network, from genesis on
+var networkForkVersions = map[string][]string{
+	MainnetNetwork: {"0x00000000", "0x01000000", "0x02000000", "0x03000000", "0x04000000"},
+	GoerliNetwork:  {"0x00001020", "0x01001020", "0x02001020", "0x03001020", "0x04001020"},
+	SepoliaNetwork: {"0x90000069", "0x90000070", "0x90000071", "0x90000072", "0x90000073"},
+	HoleskyNetwork: {"0x01017000", "0x02017000", "0x03017000", "0x04017000", "0x05017000"},
+	GnosisNetwork:  {"0x00000064", "0x01000064", "0x02000064", "0x03000064", "0x04000064"},
+}
+
+// networkForkDigests returns the fork digests of every fork of the network, along with the ones
+// computed with an empty genesis validators root (advertised by the bootnodes set up before genesis)
+func networkForkDigests(t *testing.T, network string) map[common.ForkDigest]struct{} {
+	digests := make(map[common.ForkDigest]struct{})
+	for _, rawVersion := range networkForkVersions[network] {
+		var version common.Version
+		if err := version.UnmarshalText([]byte(rawVersion)); err != nil {
+			t.Fatalf("invalid fork version %s: %v", rawVersion, err)
+		}
+		digest, err := ComputeNetworkForkDigest(network, version)
+		if err != nil {
+			t.Fatalf("unable to compute the %s fork digest of %s: %v", network, rawVersion, err)
+		}
+		digests[digest] = struct{}{}
+		digests[common.ComputeForkDigest(version, common.Root{})] = struct{}{}
+	}
+	return digests
+}
+
+func TestNetworkBootnodesForkDigests(t *testing.T) {
+	for network := range NetworkBootnodes {
+		t.Run(network, func(t *testing.T) {
+			nodes, err := GetNetworkBootnodes(network)
+			if err != nil {
+				t.Fatalf("unable to parse the bootnodes: %v", err)
+			}
+			if len(nodes) == 0 {
+				t.Fatal("no bootnodes")
+			}
+			digests := networkForkDigests(t, network)
+			for _, node := range nodes {
+				enr, _ := discv5.ParseEnrNode(node)
+				// some bootnodes don't advertise any eth2 entry
+				if enr.Eth2Data.ForkDigest == (common.ForkDigest{}) {
+					continue
+				}
+				if _, ok := digests[enr.Eth2Data.ForkDigest]; !ok {
+					t.Errorf("bootnode %s advertises the fork digest %s, which doesn't belong to %s",
+						enr.IP, enr.Eth2Data.ForkDigest, network)
+				}
+			}
+		})
+	}
+}
+
+func TestGetNetworkBootnodesUnknownNetwork(t *testing.T) {
+	if _, err := GetNetworkBootnodes("unknown"); err == nil {
+		t.Fatal("expected an error for an unknown network")
+	}
+}
+
+func TestReadBootnodesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bootnodes.yaml")
+	content := "# teku bootnodes\n- \"enr:-a\" # teku\n\n- 'enr:-b'\nenode://c@10.0.0.1:30303\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	nodes, err := ReadBootnodesFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"enr:-a", "enr:-b", "enode://c@10.0.0.1:30303"}
+	if !reflect.DeepEqual(nodes, expected) {
+		t.Fatalf("read %q, expected %q", nodes, expected)
+	}
+
+	if _, err := ReadBootnodesFile(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Fatal("expected an error for a missing file")
+	}
+}
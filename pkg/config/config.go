@@ -1,31 +1,97 @@
 package config
 
 import (
+	"encoding/hex"
+	"net"
+	"net/url"
+	"strings"
 	"time"
 
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/pkg/errors"
+	"github.com/protolambda/zrnt/eth2/beacon/common"
 	cli "github.com/urfave/cli/v2"
 )
 
+// Crawl modes supported by the crawler
+const (
+	// random walk over the DHT through the discv5 RandomNodes iterator
+	RandomCrawlMode = "random"
+	// walk the k-buckets of every discovered node with FINDNODE requests
+	TargetedCrawlMode = "targeted"
+)
+
 type Config struct {
-	IP            string
-	UDP           int
-	TCP           int
-	LogLvl        string
-	DBPath        string
-	DBEndpoint    string
-	ResetDB       bool
-	CrawlDuration time.Duration
+	IP              string
+	IPv6            bool
+	ExternalIP      string
+	UDP             int
+	TCP             int
+	LogLvl          string
+	DBPath          string
+	DBEndpoint      string
+	ResetDB         bool
+	DBPersisters    int
+	DBBatchSize     int
+	DBFlushInterval time.Duration
+	CrawlMode       string
+	CrawlDuration   time.Duration
+	PingInterval    time.Duration
+	PingFailures    int
+	Workers         int
+	CacheSize       int
+	PrivKey         string
+	KeyFile         string
+	Network         string
+	Bootnodes       []string
+	BootnodesFile   string
+	ForkDigests     []string
+	StoreForeign    bool
+	MetricsAddr     string
+	APIAddr         string
+	Output          string
+	OutputMaxSize   int
+
+	// eth2 entries advertised in the ENR of the crawler
+	EnrForkDigest  string
+	EnrForkVersion string
+	EnrAttnets     string
 }
 
 var DefaultConfig Config = Config{
-	IP:            "0.0.0.0",
-	UDP:           9001,
-	TCP:           9001,
-	LogLvl:        "info",
-	DBPath:        "eth_nodes.peerstore",
-	DBEndpoint:    "test-endpoint",
-	ResetDB:       false,
-	CrawlDuration: 1 * time.Hour,
+	IP:              "0.0.0.0",
+	IPv6:            false,
+	ExternalIP:      "",
+	UDP:             9001,
+	TCP:             9001,
+	LogLvl:          "info",
+	DBPath:          "eth_nodes.peerstore",
+	DBEndpoint:      "test-endpoint",
+	ResetDB:         false,
+	DBPersisters:    4,
+	DBBatchSize:     256,
+	DBFlushInterval: 1 * time.Second,
+	CrawlMode:       RandomCrawlMode,
+	CrawlDuration:   0, // run until the crawler gets interrupted
+	PingInterval:    0, // liveness probing disabled
+	PingFailures:    3,
+	Workers:         8,
+	CacheSize:       0, // unbounded
+	PrivKey:         "",
+	KeyFile:         "",
+	Network:         MainnetNetwork,
+	Bootnodes:       []string{},
+	BootnodesFile:   "",
+	ForkDigests:     []string{},
+	StoreForeign:    false,
+	MetricsAddr:     "",  // metrics disabled
+	APIAddr:         "",  // api disabled
+	Output:          "",  // store the nodes in the db
+	OutputMaxSize:   100, // MB
+
+	EnrForkDigest:  "",
+	EnrForkVersion: "",
+	EnrAttnets:     "0x0000000000000000",
 }
 
 func (c *Config) Apply(ctx *cli.Context) {
@@ -33,17 +99,219 @@ func (c *Config) Apply(ctx *cli.Context) {
 	if ctx.IsSet("log-level") {
 		c.LogLvl = ctx.String("log-level")
 	}
+	if ctx.IsSet("listen-ip") {
+		c.IP = ctx.String("listen-ip")
+	}
+	if ctx.IsSet("ipv6") {
+		c.IPv6 = ctx.Bool("ipv6")
+	}
 	// read the port from the ctx
 	if ctx.IsSet("port") {
 		port := ctx.Int("port")
 		c.UDP = port
 		c.TCP = port
 	}
+	// specific ports have priority over the generic one
+	if ctx.IsSet("udp-port") {
+		c.UDP = ctx.Int("udp-port")
+	}
+	if ctx.IsSet("tcp-port") {
+		c.TCP = ctx.Int("tcp-port")
+	}
+	if ctx.IsSet("external-ip") {
+		c.ExternalIP = ctx.String("external-ip")
+	}
 	if ctx.IsSet("db-endpoint") {
 		c.DBEndpoint = ctx.String("db-endpoint")
 	}
 	if ctx.IsSet("reset-db") {
 		c.ResetDB = ctx.Bool("reset-db")
 	}
+	if ctx.IsSet("db-persisters") {
+		c.DBPersisters = ctx.Int("db-persisters")
+	}
+	if ctx.IsSet("db-batch-size") {
+		c.DBBatchSize = ctx.Int("db-batch-size")
+	}
+	if ctx.IsSet("db-flush-interval") {
+		c.DBFlushInterval = ctx.Duration("db-flush-interval")
+	}
+	if ctx.IsSet("mode") {
+		c.CrawlMode = ctx.String("mode")
+	}
+	if ctx.IsSet("duration") {
+		c.CrawlDuration = ctx.Duration("duration")
+	}
+	if ctx.IsSet("ping-interval") {
+		c.PingInterval = ctx.Duration("ping-interval")
+	}
+	if ctx.IsSet("ping-max-failures") {
+		c.PingFailures = ctx.Int("ping-max-failures")
+	}
+	if ctx.IsSet("workers") {
+		c.Workers = ctx.Int("workers")
+	}
+	if ctx.IsSet("cache-size") {
+		c.CacheSize = ctx.Int("cache-size")
+	}
+	if ctx.IsSet("priv-key") {
+		c.PrivKey = ctx.String("priv-key")
+	}
+	if ctx.IsSet("key-file") {
+		c.KeyFile = ctx.String("key-file")
+	}
+	if ctx.IsSet("network") {
+		c.Network = ctx.String("network")
+	}
+	if ctx.IsSet("bootnodes") {
+		c.Bootnodes = ctx.StringSlice("bootnodes")
+	}
+	if ctx.IsSet("bootnodes-file") {
+		c.BootnodesFile = ctx.String("bootnodes-file")
+	}
+	if ctx.IsSet("fork-digest") {
+		c.ForkDigests = ctx.StringSlice("fork-digest")
+	}
+	if ctx.IsSet("store-foreign") {
+		c.StoreForeign = ctx.Bool("store-foreign")
+	}
+	if ctx.IsSet("metrics-addr") {
+		c.MetricsAddr = ctx.String("metrics-addr")
+	}
+	if ctx.IsSet("api-addr") {
+		c.APIAddr = ctx.String("api-addr")
+	}
+	if ctx.IsSet("output") {
+		c.Output = ctx.String("output")
+	}
+	if ctx.IsSet("output-max-size") {
+		c.OutputMaxSize = ctx.Int("output-max-size")
+	}
+	if ctx.IsSet("enr-fork-digest") {
+		c.EnrForkDigest = ctx.String("enr-fork-digest")
+	}
+	if ctx.IsSet("enr-fork-version") {
+		c.EnrForkVersion = ctx.String("enr-fork-version")
+	}
+	if ctx.IsSet("enr-attnets") {
+		c.EnrAttnets = ctx.String("enr-attnets")
+	}
 	// more args?
 }
+
+// Snapshot returns a copy of the config that can be stored along with the crawl,
+// without the private key nor the password of the db-endpoint
+func (c *Config) Snapshot() Config {
+	snapshot := *c
+	if snapshot.PrivKey != "" {
+		snapshot.PrivKey = "xxxxx"
+	}
+	if endpoint, err := url.Parse(snapshot.DBEndpoint); err == nil {
+		snapshot.DBEndpoint = endpoint.Redacted()
+	}
+	return snapshot
+}
+
+// GetBootnodes returns the custom bootnodes given through the flags or the bootnodes-file,
+// or the built-in set of the selected network if no custom ones were provided
+func (c *Config) GetBootnodes() ([]*enode.Node, error) {
+	rawNodes := append([]string{}, c.Bootnodes...)
+	if c.BootnodesFile != "" {
+		fileNodes, err := ReadBootnodesFile(c.BootnodesFile)
+		if err != nil {
+			return nil, err
+		}
+		rawNodes = append(rawNodes, fileNodes...)
+	}
+
+	if len(rawNodes) == 0 {
+		return GetNetworkBootnodes(c.Network)
+	}
+	bootnodes, err := ParseBootnodes(rawNodes)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to parse custom bootnodes")
+	}
+	return bootnodes, nil
+}
+
+// GetListenIP returns the IP where the discovery service will listen.
+// If IPv6 is enabled and no custom listen-ip was given, it listens dual-stack on "::"
+func (c *Config) GetListenIP() (net.IP, error) {
+	if c.IPv6 && c.IP == DefaultConfig.IP {
+		return net.IPv6unspecified, nil
+	}
+	ip := net.ParseIP(c.IP)
+	if ip == nil {
+		return nil, errors.New("invalid listen ip " + c.IP)
+	}
+	return ip, nil
+}
+
+// GetExternalIP returns the static IP that the crawler will advertise in its ENR (nil if none was given)
+func (c *Config) GetExternalIP() (net.IP, error) {
+	if c.ExternalIP == "" {
+		return nil, nil
+	}
+	ip := net.ParseIP(c.ExternalIP)
+	if ip == nil {
+		return nil, errors.New("invalid external ip " + c.ExternalIP)
+	}
+	return ip, nil
+}
+
+// GetForkDigests parses the hex encoded fork digests that the crawler should index
+func (c *Config) GetForkDigests() ([]common.ForkDigest, error) {
+	forkDigests := make([]common.ForkDigest, 0, len(c.ForkDigests))
+	for _, rawDigest := range c.ForkDigests {
+		var forkDigest common.ForkDigest
+		err := forkDigest.UnmarshalText([]byte(rawDigest))
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid fork digest "+rawDigest)
+		}
+		forkDigests = append(forkDigests, forkDigest)
+	}
+	return forkDigests, nil
+}
+
+// GetEnrEth2Data returns the Eth2Data that the crawler will advertise in its ENR.
+// The fork digest is either the given one, or the one computed from the given fork version and
+// the selected network. It returns false if none of them were provided
+func (c *Config) GetEnrEth2Data() (*common.Eth2Data, bool, error) {
+	if c.EnrForkDigest == "" && c.EnrForkVersion == "" {
+		return nil, false, nil
+	}
+	eth2Data := &common.Eth2Data{
+		NextForkEpoch: common.FAR_FUTURE_EPOCH,
+	}
+
+	if c.EnrForkVersion != "" {
+		err := eth2Data.NextForkVersion.UnmarshalText([]byte(c.EnrForkVersion))
+		if err != nil {
+			return nil, true, errors.Wrap(err, "invalid enr fork version "+c.EnrForkVersion)
+		}
+		eth2Data.ForkDigest, err = ComputeNetworkForkDigest(c.Network, eth2Data.NextForkVersion)
+		if err != nil {
+			return nil, true, err
+		}
+	}
+	// an explicit fork digest has priority over the computed one
+	if c.EnrForkDigest != "" {
+		err := eth2Data.ForkDigest.UnmarshalText([]byte(c.EnrForkDigest))
+		if err != nil {
+			return nil, true, errors.Wrap(err, "invalid enr fork digest "+c.EnrForkDigest)
+		}
+	}
+	return eth2Data, true, nil
+}
+
+// GetEnrAttnets returns the attnets bitvector that the crawler will advertise in its ENR
+func (c *Config) GetEnrAttnets() ([]byte, error) {
+	attnets, err := hex.DecodeString(strings.TrimPrefix(c.EnrAttnets, "0x"))
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid enr attnets "+c.EnrAttnets)
+	}
+	if len(attnets) != common.ATTESTATION_SUBNET_COUNT/8 {
+		return nil, errors.Errorf("enr attnets must be %d bytes long", common.ATTESTATION_SUBNET_COUNT/8)
+	}
+	return attnets, nil
+}
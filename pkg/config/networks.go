@@ -0,0 +1,32 @@
+package config
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/protolambda/zrnt/eth2/beacon/common"
+)
+
+// NetworkGenesisValidatorsRoots links each supported network with its genesis validators root,
+// which is needed to compute the fork digests of the network
+var NetworkGenesisValidatorsRoots = map[string]string{
+	MainnetNetwork: "0x4b363db94e286120d76eb905340fdd4e54bfe9f06bf33ff6cf5ad27f511bfe95",
+	GoerliNetwork:  "0x043db0d9a83813551ee2f33450d23797757d430911a9320530ad8a0eabc43efb",
+	SepoliaNetwork: "0xd8ea171f3c94aea21ebc42a1ed61052acf3f9209c00e4efbaaddac09ed9b8078",
+	HoleskyNetwork: "0x9143aa7c615a7f7115e2b6aac319c03529df8242ae705fba9df39b79c59fa8b1",
+	GnosisNetwork:  "0xf5dcb5564e829aab27264b9becd5dfaa017085611224cb3036f573368dbb9d47",
+}
+
+// ComputeNetworkForkDigest computes the fork digest of the given network for the given fork version
+func ComputeNetworkForkDigest(network string, forkVersion common.Version) (common.ForkDigest, error) {
+	rawRoot, ok := NetworkGenesisValidatorsRoots[strings.ToLower(network)]
+	if !ok {
+		return common.ForkDigest{}, errors.New("unknown network " + network)
+	}
+	var genesisRoot common.Root
+	err := genesisRoot.UnmarshalText([]byte(rawRoot))
+	if err != nil {
+		return common.ForkDigest{}, errors.Wrap(err, "invalid genesis validators root for network "+network)
+	}
+	return common.ComputeForkDigest(forkVersion, genesisRoot), nil
+}
@@ -2,18 +2,26 @@ package crawler
 
 import (
 	"context"
+	"crypto/ecdsa"
 	"encoding/hex"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/migalabs/armiarma/src/utils"
+	"github.com/migalabs/eth-light-crawler/pkg/api"
 	"github.com/migalabs/eth-light-crawler/pkg/config"
 	"github.com/migalabs/eth-light-crawler/pkg/db"
 	"github.com/migalabs/eth-light-crawler/pkg/discv5"
+	"github.com/migalabs/eth-light-crawler/pkg/export"
 	ut "github.com/migalabs/eth-light-crawler/pkg/utils"
 
 	"github.com/pkg/errors"
 
 	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethereum/go-ethereum/p2p/enr"
 	"github.com/protolambda/zrnt/eth2/beacon/common"
 	log "github.com/sirupsen/logrus"
 )
@@ -29,128 +37,412 @@ type Crawler struct {
 	duration time.Duration
 
 	ethNode       *enode.LocalNode
+	enodeDB       *enode.DB
 	discv5Service *discv5.Discv5Service
-	dbClient      *db.DBClient
+	prober        *discv5.Prober
+	store         db.Store
+	// api serving the nodes of the store during the crawl (if enabled)
+	apiServer *api.Server
 
-	enrCache map[enode.ID]int64
+	enrCache *enrCache
+	stats    *crawlStats
+	// session recorded in the crawls table
+	crawl *db.Crawl
+
+	mode      string
+	bootnodes []*enode.Node
 }
 
-func New(ctx context.Context, dbEndpoint string, dbPath string, port int, resetDB bool) (*Crawler, error) {
-	// Generate a new PrivKey
-	privK, err := ut.GenNewPrivKey()
+func New(ctx context.Context, conf *config.Config) (*Crawler, error) {
+	// Load or generate the PrivKey that identifies the crawler
+	privK, err := resolvePrivKey(conf)
+	if err != nil {
+		return nil, err
+	}
+
+	// Validate the crawl mode, the fork digests and the bootnodes before opening any DB
+	if conf.CrawlMode != config.RandomCrawlMode && conf.CrawlMode != config.TargetedCrawlMode {
+		return nil, errors.New("unknown crawl mode " + conf.CrawlMode)
+	}
+	if conf.PingInterval > 0 && conf.PingFailures < 1 {
+		return nil, errors.New("ping-max-failures must be at least 1")
+	}
+	forkDigests, err := conf.GetForkDigests()
+	if err != nil {
+		return nil, err
+	}
+
+	bootnodes, err := conf.GetBootnodes()
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to load the bootnodes")
+	}
+	log.Infof("loaded %d bootnodes", len(bootnodes))
+
+	listenIP, err := conf.GetListenIP()
 	if err != nil {
-		return nil, errors.Wrap(err, "error generating privkey")
+		return nil, err
+	}
+	externalIP, err := conf.GetExternalIP()
+	if err != nil {
+		return nil, err
 	}
 
 	// Init the ethereum peerstore
-	enodeDB, err := enode.OpenDB(dbPath)
+	enodeDB, err := enode.OpenDB(conf.DBPath)
 	if err != nil {
 		return nil, err
 	}
 
-	// Create a new
-	sqlDB, err := db.NewDBClient(ctx, dbEndpoint, true, resetDB)
+	// Open the output file, or the storage backend selected by the db-endpoint
+	store, err := openStore(ctx, conf)
 	if err != nil {
+		enodeDB.Close()
 		return nil, err
 	}
+	// release both of them if the crawler can't be composed
+	closeStores := func() {
+		store.Close()
+		enodeDB.Close()
+	}
 
 	// Generate a Enode with custom ENR
 	ethNode := enode.NewLocalNode(enodeDB, privK)
+	setLocalEndpoints(ethNode, externalIP, conf.UDP, conf.TCP)
+	err = setLocalEth2Entries(ethNode, conf)
+	if err != nil {
+		closeStores()
+		return nil, err
+	}
 
-	// generate the cache of node_ids > seq numbers
-	enrCache := make(map[enode.ID]uint64)
+	// generate the cache of node_ids > seq numbers (to tell new and updated nodes apart),
+	// warmed up with the nodes already in the DB
+	enrCache := newEnrCache(conf.CacheSize)
+	seqs, err := store.GetEnrSeqs(conf.CacheSize)
+	if err != nil {
+		closeStores()
+		return nil, errors.Wrap(err, "unable to load the enr cache")
+	}
+	enrCache.Load(seqs)
+	log.Infof("loaded %d known nodes into the enr cache", enrCache.Len())
+
+	stats := new(crawlStats)
+	forkCounter := newForkDigestCounter()
+	// the ID of the crawl is set by Run, before the discovery starts
+	crawl := &db.Crawl{
+		PeerID: ethNode.ID(),
+		Config: conf.Snapshot(),
+	}
+
+	// liveness prober of the indexed nodes (only if enabled)
+	var prober *discv5.Prober
 
 	// define the Handler for when we discover a new ENR
 	enrHandler := func(node *enode.Node) {
 		// check if the node is valid
 		err := node.ValidateComplete()
 		if err != nil {
-			log.Warnf("error validating the ENR - ", err.Error())
+			log.Warnf("error validating the ENR - %s", err.Error())
+			stats.addFailed()
 		}
 		// extract the information from the enode
-		id := node.ID()
-		seq := node.Seq()
-		ip := node.IP()
-		udp := node.UDP()
-		tcp := node.TCP()
-		pubkey := node.Pubkey()
-
-		// Retrieve the Fork Digest and the attestnets
-		eth2Data, ok, err := utils.ParseNodeEth2Data(*node)
-		if !ok {
-			eth2Data = new(common.Eth2Data)
-		} else {
-			if err != nil {
-				log.Error(errors.Wrap(err, "eth2 data parsing error"))
-				// eth2Data = new(common.Eth2Data)
-			}
-		}
-
-		attnets, ok, err := discv5.ParseAttnets(*node)
-		if !ok {
-			attnets = new(discv5.Attnets)
-		} else {
-			if err != nil {
-				log.Error(errors.Wrap(err, "attnets parsing err"))
-				// attnets = new(discv5.Attnets)
-			}
+		enrNode, entryErrs := discv5.ParseEnrNode(node)
+		for _, entryErr := range entryErrs {
+			log.Error(entryErr)
+			enrParseErrors.WithLabelValues(entryErr.Entry).Inc()
 		}
-		// create a new ENR node
-		enrNode := discv5.NewEnrNode(id)
-
-		// add all the fields from the CL network
-		enrNode.Seq = seq
-		enrNode.IP = ip
-		enrNode.TCP = tcp
-		enrNode.UDP = udp
-		enrNode.Pubkey = pubkey
-		enrNode.Eth2Data = eth2Data
-		enrNode.Attnets = attnets
+		eth2Data, attnets, syncnets := enrNode.Eth2Data, enrNode.Attnets, enrNode.Syncnets
 
 		log.WithFields(log.Fields{
-			"node_id":     id,
-			"ip":          ip,
-			"udp":         udp,
-			"tcp":         tcp,
+			"node_id":     enrNode.ID,
+			"ip":          enrNode.IP,
+			"udp":         enrNode.UDP,
+			"tcp":         enrNode.TCP,
+			"ip6":         enrNode.IP6,
 			"fork_digest": eth2Data.ForkDigest,
 			"fork_epoch":  eth2Data.NextForkEpoch,
 			"attnets":     hex.EncodeToString(attnets.Raw[:]),
 			"att_number":  attnets.NetNumber,
+			"syncnets":    hex.EncodeToString(syncnets.Raw[:]),
+			"sync_number": syncnets.NetNumber,
 			"enr":         node.String(),
 		}).Info("Eth node found")
+		stats.addDiscovered()
+		forkCounter.Observe(enrNode.ID, eth2Data.ForkDigest)
 
-		// decide whether we need to insert or update an existing
-		prevSeq, ok := enrCache[enrNode.ID]
-		if !ok { // Insert not previously tracked enr
-			sqlDB.InsertIntoDB(enrNode)
-		} else if enrNode.Seq > prevSeq { // Update the the data of the given Node
-			sqlDB.UpdateInDB(enrNode)
+		// check if the node belongs to the fork digests that we index
+		if !matchesForkDigest(forkDigests, eth2Data.ForkDigest) {
+			stats.addForeign()
+			if !conf.StoreForeign {
+				return
+			}
+			// the DB keeps the ENR with the highest seq
+			if err := store.UpsertForeignEnr(&db.ForeignEnr{EnrNode: enrNode}); err != nil {
+				log.Error(err)
+			}
+			return
+		}
+
+		if prober != nil {
+			prober.Track(node)
+		}
+
+		// keep track of every version of the ENR
+		if err := store.InsertEnrHistory(&db.EnrHistory{EnrNode: enrNode, CrawlID: crawl.ID}); err != nil {
+			log.Error(err)
+		}
+
+		// upsert the ENR (the DB keeps the one with the highest seq),
+		// the cache only tells whether it is a new or an updated node
+		if err := store.UpsertEnr(enrNode); err != nil {
+			log.Error(err)
+		}
+		prevSeq, ok := enrCache.Update(enrNode.ID, enrNode.Seq)
+		if !ok {
+			stats.addNew()
+		} else if enrNode.Seq > prevSeq {
+			stats.addUpdated()
 		}
-		enrCache[enrNode.ID] = enrNode.Seq
 	}
 
 	// Generate the Discovery5 service
-	discv5Serv, err := discv5.NewService(ctx, port, privK, ethNode, config.EthBootonodes, enrHandler)
+	discv5Serv, err := discv5.NewService(ctx, listenIP, conf.UDP, privK, ethNode, bootnodes, conf.Workers, enrHandler)
 	if err != nil {
+		closeStores()
 		return nil, errors.Wrap(err, "unable to generate the discv5 service")
 	}
 
+	if conf.PingInterval > 0 {
+		prober = discv5.NewProber(discv5Serv, conf.PingInterval, conf.PingFailures, func(result *discv5.PingResult) {
+			if err := store.UpsertPingResult(&db.PingResult{PingResult: result, CrawlID: crawl.ID}); err != nil {
+				log.Error(err)
+			}
+		})
+	}
+
+	var apiServer *api.Server
+	if conf.APIAddr != "" {
+		apiServer = api.NewServer(store, conf.APIAddr)
+	}
+
 	return &Crawler{
 		ctx:           ctx,
 		ethNode:       ethNode,
-		dbClient:      sqlDB,
+		enodeDB:       enodeDB,
+		store:         store,
+		apiServer:     apiServer,
 		discv5Service: discv5Serv,
+		prober:        prober,
+		enrCache:      enrCache,
+		stats:         stats,
+		crawl:         crawl,
+		mode:          conf.CrawlMode,
+		bootnodes:     bootnodes,
 	}, nil
 }
 
+// openStore returns the rotating file store if an output file was given (so that no db is needed),
+// or the storage backend of the db-endpoint otherwise
+func openStore(ctx context.Context, conf *config.Config) (db.Store, error) {
+	if conf.Output != "" {
+		fileStore, err := export.NewFileStore(conf.Output, int64(conf.OutputMaxSize)<<20)
+		if err != nil {
+			return nil, err
+		}
+		return fileStore, nil
+	}
+	return db.NewStore(ctx, conf.DBEndpoint, conf.ResetDB, conf.DBPersisters, conf.DBBatchSize, conf.DBFlushInterval)
+}
+
+// setLocalEndpoints sets the static IP and ports that the crawler advertises in its ENR,
+// so that other nodes get a reachable record even if the NAT prediction is wrong
+func setLocalEndpoints(ethNode *enode.LocalNode, externalIP net.IP, udp int, tcp int) {
+	if externalIP != nil {
+		ethNode.SetStaticIP(externalIP)
+	}
+	ethNode.SetFallbackUDP(udp)
+	if tcp > 0 {
+		if externalIP != nil && externalIP.To4() == nil {
+			ethNode.Set(enr.TCP6(tcp))
+		} else {
+			ethNode.Set(enr.TCP(tcp))
+		}
+	}
+}
+
+// setLocalEth2Entries adds the eth2 and attnets entries to the ENR of the crawler (if requested),
+// so that it looks like a beacon node of the network that we are measuring
+func setLocalEth2Entries(ethNode *enode.LocalNode, conf *config.Config) error {
+	eth2Data, ok, err := conf.GetEnrEth2Data()
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return nil
+	}
+	attnets, err := conf.GetEnrAttnets()
+	if err != nil {
+		return err
+	}
+	eth2Entry, err := discv5.NewEth2ENREntry(eth2Data)
+	if err != nil {
+		return errors.Wrap(err, "unable to compose the eth2 enr entry")
+	}
+	ethNode.Set(eth2Entry)
+	ethNode.Set(utils.AttnetsENREntry(attnets))
+
+	log.WithFields(log.Fields{
+		"fork_digest": eth2Data.ForkDigest,
+		"attnets":     hex.EncodeToString(attnets),
+	}).Info("advertising eth2 entries in the local ENR")
+	return nil
+}
+
+// matchesForkDigest returns true if no fork digest filter was set, or if the given digest is among the tracked ones
+func matchesForkDigest(forkDigests []common.ForkDigest, forkDigest common.ForkDigest) bool {
+	if len(forkDigests) == 0 {
+		return true
+	}
+	for _, digest := range forkDigests {
+		if digest == forkDigest {
+			return true
+		}
+	}
+	return false
+}
+
+// resolvePrivKey returns the key given through the config, the one stored at the key-file
+// (created if it doesn't exist), or a new one if none of them were provided
+func resolvePrivKey(conf *config.Config) (*ecdsa.PrivateKey, error) {
+	switch {
+	case conf.PrivKey != "":
+		return ut.ParsePrivKey(conf.PrivKey)
+	case conf.KeyFile != "":
+		return ut.LoadOrGenPrivKey(conf.KeyFile)
+	default:
+		log.Warn("no priv-key or key-file provided, the crawler will use a new identity")
+		privK, err := ut.GenNewPrivKey()
+		if err != nil {
+			return nil, errors.Wrap(err, "error generating privkey")
+		}
+		return privK, nil
+	}
+}
+
 func (c *Crawler) Run(duration time.Duration) error {
-	// if duration has not been set, run until Crtl+C
-	c.discv5Service.Run()
-	// otherwise, run it for X time
+	var ctx context.Context
+	var cancel context.CancelFunc
+	if duration > 0 {
+		// run it for X time
+		ctx, cancel = context.WithTimeout(c.ctx, duration)
+	} else {
+		// if duration has not been set, run until Crtl+C
+		ctx, cancel = context.WithCancel(c.ctx)
+	}
+	defer cancel()
 
+	c.startT = time.Now()
+	c.duration = duration
+
+	// record the crawl session, so that its observations can reference it
+	c.crawl.StartTime = c.startT
+	c.crawl.Status = db.CrawlRunning
+	if err := c.store.InsertCrawl(c.crawl); err != nil {
+		c.discv5Service.Close()
+		c.store.Close()
+		c.enodeDB.Close()
+		return err
+	}
+	log.Infof("starting crawl %d", c.crawl.ID)
+
+	// listen for the SIGINT/SIGTERM signals to shut down gracefully
+	sigC := make(chan os.Signal, 1)
+	signal.Notify(sigC, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigC)
+
+	if c.prober != nil {
+		go c.prober.Run()
+	}
+	if c.apiServer != nil {
+		go func() {
+			if err := c.apiServer.Serve(c.ctx); err != nil {
+				log.Error(err)
+			}
+		}()
+	}
+
+	discoveryDoneC := make(chan struct{})
+	go func() {
+		defer close(discoveryDoneC)
+		switch c.mode {
+		case config.TargetedCrawlMode:
+			c.discv5Service.RunTargeted(c.bootnodes, c.routingTableHandler)
+		default:
+			c.discv5Service.Run()
+		}
+	}()
+
+	status := db.CrawlCompleted
+	select {
+	case <-ctx.Done():
+		log.Info("crawl duration reached, closing the crawler")
+	case sig := <-sigC:
+		log.Infof("received %s signal, closing the crawler", sig.String())
+		status = db.CrawlInterrupted
+	case <-discoveryDoneC:
+		log.Info("discovery service finished, closing the crawler")
+	}
+
+	c.close(discoveryDoneC, status)
+	log.Infof("crawler closed after %s", time.Since(c.startT))
+	c.stats.logSummary()
+	log.Debugf("%d nodes left in the enr cache", c.enrCache.Len())
 	return nil
 }
 
+// routingTableHandler persists the neighbors of each node visited during the targeted crawl
+func (c *Crawler) routingTableHandler(rt *discv5.RoutingTable) {
+	if rt.Err != nil {
+		c.stats.addFailed()
+	}
+	if err := c.store.InsertRoutingTable(&db.RoutingTable{RoutingTable: rt, CrawlID: c.crawl.ID}); err != nil {
+		log.Error(err)
+	}
+}
+
+// close stops the discovery, waits until the handler is done with the last node,
+// records the results of the crawl and flushes the pending items in the DB before closing the peerstore.
+// The api is stopped first, so that no request reaches the closed store
+func (c *Crawler) close(discoveryDoneC chan struct{}, status string) {
+	if c.apiServer != nil {
+		c.apiServer.Close()
+	}
+	if c.prober != nil {
+		c.prober.Close()
+	}
+	c.discv5Service.Close()
+	<-discoveryDoneC
+
+	c.crawl.EndTime = time.Now()
+	c.crawl.Status = status
+	c.stats.fill(c.crawl)
+	if err := c.store.UpdateCrawl(c.crawl); err != nil {
+		log.Error(err)
+	}
+
+	c.store.Close()
+	c.enodeDB.Close()
+}
+
 func (c *Crawler) ID() string {
 	return c.ethNode.ID().String()
 }
+
+// Store returns the storage backend of the crawler (i.e. to serve the crawled nodes)
+func (c *Crawler) Store() db.Store {
+	return c.store
+}
+
+// ENR returns the record that the crawler advertises in the network
+func (c *Crawler) ENR() string {
+	return c.ethNode.Node().String()
+}
@@ -0,0 +1,71 @@
+package crawler
+
+import (
+	"container/list"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/p2p/enode"
+)
+
+type cachedEnr struct {
+	id  enode.ID
+	seq uint64
+}
+
+// enrCache keeps the latest seq number of each indexed node, so that the handler can decide
+// whether an ENR is new, updated or already known. It is safe for concurrent use, and if a
+// size limit is given, the least recently seen nodes get evicted once it is reached
+type enrCache struct {
+	m     sync.Mutex
+	limit int
+	nodes map[enode.ID]*list.Element
+	lru   *list.List
+}
+
+// newEnrCache returns an empty cache that keeps up to limit nodes (0 = unbounded)
+func newEnrCache(limit int) *enrCache {
+	return &enrCache{
+		limit: limit,
+		nodes: make(map[enode.ID]*list.Element),
+		lru:   list.New(),
+	}
+}
+
+// Load fills the cache with the given node_id -> seq numbers (i.e. the ones already in the DB)
+func (c *enrCache) Load(seqs map[enode.ID]uint64) {
+	for id, seq := range seqs {
+		c.Update(id, seq)
+	}
+}
+
+// Update returns the previous seq number of the node and whether it was cached,
+// keeping the given seq number if it is newer than the cached one
+func (c *enrCache) Update(id enode.ID, seq uint64) (uint64, bool) {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	if elem, ok := c.nodes[id]; ok {
+		c.lru.MoveToFront(elem)
+		cached := elem.Value.(*cachedEnr)
+		prevSeq := cached.seq
+		if seq > prevSeq {
+			cached.seq = seq
+		}
+		return prevSeq, true
+	}
+
+	c.nodes[id] = c.lru.PushFront(&cachedEnr{id: id, seq: seq})
+	if c.limit > 0 && c.lru.Len() > c.limit {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.nodes, oldest.Value.(*cachedEnr).id)
+	}
+	return 0, false
+}
+
+// Len returns the number of cached nodes
+func (c *enrCache) Len() int {
+	c.m.Lock()
+	defer c.m.Unlock()
+	return c.lru.Len()
+}
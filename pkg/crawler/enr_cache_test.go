@@ -0,0 +1,129 @@
+package crawler
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/p2p/enode"
+)
+
+func testID(b byte) enode.ID {
+	var id enode.ID
+	id[0] = b
+	return id
+}
+
+func TestEnrCacheUpdate(t *testing.T) {
+	tests := []struct {
+		name     string
+		seq      uint64
+		prevSeq  uint64
+		cached   bool
+		finalSeq uint64
+	}{
+		{name: "new node", seq: 5, prevSeq: 0, cached: false, finalSeq: 5},
+		{name: "same seq", seq: 5, prevSeq: 5, cached: true, finalSeq: 5},
+		{name: "higher seq", seq: 7, prevSeq: 5, cached: true, finalSeq: 7},
+		{name: "lower seq keeps the newer one", seq: 6, prevSeq: 7, cached: true, finalSeq: 7},
+	}
+
+	cache := newEnrCache(0)
+	id := testID(1)
+	for _, test := range tests {
+		prevSeq, cached := cache.Update(id, test.seq)
+		if prevSeq != test.prevSeq || cached != test.cached {
+			t.Fatalf("%s: Update returned (%d, %t), expected (%d, %t)", test.name, prevSeq, cached, test.prevSeq, test.cached)
+		}
+		// a repeated update with seq 0 returns the cached seq without changing it
+		if seq, _ := cache.Update(id, 0); seq != test.finalSeq {
+			t.Fatalf("%s: cached seq %d, expected %d", test.name, seq, test.finalSeq)
+		}
+	}
+	if cache.Len() != 1 {
+		t.Fatalf("cache has %d nodes, expected 1", cache.Len())
+	}
+}
+
+func TestEnrCacheEviction(t *testing.T) {
+	tests := []struct {
+		name    string
+		limit   int
+		updates []byte
+		cached  []byte
+		evicted []byte
+	}{
+		{
+			name:    "unbounded",
+			limit:   0,
+			updates: []byte{1, 2, 3, 4},
+			cached:  []byte{1, 2, 3, 4},
+		},
+		{
+			name:    "evicts the oldest",
+			limit:   2,
+			updates: []byte{1, 2, 3},
+			cached:  []byte{2, 3},
+			evicted: []byte{1},
+		},
+		{
+			name:    "updates refresh the nodes",
+			limit:   2,
+			updates: []byte{1, 2, 1, 3},
+			cached:  []byte{1, 3},
+			evicted: []byte{2},
+		},
+		{
+			name:    "limit of one",
+			limit:   1,
+			updates: []byte{1, 2, 3},
+			cached:  []byte{3},
+			evicted: []byte{1, 2},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cache := newEnrCache(test.limit)
+			for _, b := range test.updates {
+				cache.Update(testID(b), 1)
+			}
+			if cache.Len() != len(test.cached) {
+				t.Fatalf("cache has %d nodes, expected %d", cache.Len(), len(test.cached))
+			}
+			// check the evicted nodes first, since checking the cached ones refreshes them
+			for _, b := range test.evicted {
+				if _, ok := cache.nodes[testID(b)]; ok {
+					t.Errorf("node %d should have been evicted", b)
+				}
+			}
+			for _, b := range test.cached {
+				if _, ok := cache.nodes[testID(b)]; !ok {
+					t.Errorf("node %d should be cached", b)
+				}
+			}
+		})
+	}
+}
+
+func TestEnrCacheLoad(t *testing.T) {
+	cache := newEnrCache(0)
+	cache.Load(map[enode.ID]uint64{
+		testID(1): 3,
+		testID(2): 9,
+	})
+	if cache.Len() != 2 {
+		t.Fatalf("cache has %d nodes, expected 2", cache.Len())
+	}
+	if seq, ok := cache.Update(testID(2), 10); !ok || seq != 9 {
+		t.Fatalf("Update returned (%d, %t), expected (9, true)", seq, ok)
+	}
+
+	// the limit applies to the loaded nodes too
+	limited := newEnrCache(1)
+	limited.Load(map[enode.ID]uint64{
+		testID(1): 3,
+		testID(2): 9,
+	})
+	if limited.Len() != 1 {
+		t.Fatalf("limited cache has %d nodes, expected 1", limited.Len())
+	}
+}
@@ -0,0 +1,71 @@
+package crawler
+
+import (
+	"sync"
+
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/migalabs/eth-light-crawler/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/protolambda/zrnt/eth2/beacon/common"
+)
+
+var (
+	enrsDiscovered = newEnrsCounter("discovered", "ENRs that went through the handler (including repeated ones).")
+	enrsNew        = newEnrsCounter("new", "ENRs of nodes that weren't known by the crawler.")
+	enrsUpdated    = newEnrsCounter("updated", "ENRs with a higher seq number than the known one.")
+	enrsForeign    = newEnrsCounter("foreign", "ENRs that didn't match the fork digest filter.")
+	enrsFailed     = newEnrsCounter("failed", "ENRs that failed the validation, or nodes that didn't reply to the targeted crawl.")
+
+	enrParseErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "crawler",
+		Name:      "enr_parse_errors_total",
+		Help:      "Errors parsing the eth2 entries of the discovered ENRs, by entry.",
+	}, []string{"entry"})
+
+	forkDigestNodes = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "crawler",
+		Name:      "fork_digest_nodes",
+		Help:      "Distinct nodes seen during the crawl, by the fork digest of their latest ENR.",
+	}, []string{"fork_digest"})
+)
+
+func newEnrsCounter(name string, help string) prometheus.Counter {
+	return promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "crawler",
+		Name:      "enrs_" + name + "_total",
+		Help:      help,
+	})
+}
+
+// forkDigestCounter keeps track of the fork digest of each node seen during the crawl,
+// so that the fork_digest_nodes gauge counts every node once
+type forkDigestCounter struct {
+	m     sync.Mutex
+	nodes map[enode.ID]common.ForkDigest
+}
+
+func newForkDigestCounter() *forkDigestCounter {
+	return &forkDigestCounter{
+		nodes: make(map[enode.ID]common.ForkDigest),
+	}
+}
+
+// Observe moves the node to the gauge of the given fork digest (i.e. after a fork upgrade)
+func (c *forkDigestCounter) Observe(id enode.ID, forkDigest common.ForkDigest) {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	prev, ok := c.nodes[id]
+	if ok && prev == forkDigest {
+		return
+	}
+	if ok {
+		forkDigestNodes.WithLabelValues(prev.String()).Dec()
+	}
+	c.nodes[id] = forkDigest
+	forkDigestNodes.WithLabelValues(forkDigest.String()).Inc()
+}
@@ -0,0 +1,64 @@
+package crawler
+
+import (
+	"sync/atomic"
+
+	"github.com/migalabs/eth-light-crawler/pkg/db"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// crawlStats keeps the counters of the nodes that went through the ENR handler
+type crawlStats struct {
+	discovered uint64
+	new        uint64
+	updated    uint64
+	// nodes that didn't match the fork digest filter
+	foreign uint64
+	// nodes with an invalid ENR, or that didn't reply to the targeted crawl
+	failed uint64
+}
+
+func (s *crawlStats) addDiscovered() {
+	atomic.AddUint64(&s.discovered, 1)
+	enrsDiscovered.Inc()
+}
+
+func (s *crawlStats) addNew() {
+	atomic.AddUint64(&s.new, 1)
+	enrsNew.Inc()
+}
+
+func (s *crawlStats) addUpdated() {
+	atomic.AddUint64(&s.updated, 1)
+	enrsUpdated.Inc()
+}
+
+func (s *crawlStats) addForeign() {
+	atomic.AddUint64(&s.foreign, 1)
+	enrsForeign.Inc()
+}
+
+func (s *crawlStats) addFailed() {
+	atomic.AddUint64(&s.failed, 1)
+	enrsFailed.Inc()
+}
+
+// fill copies the counters into the results of the crawl
+func (s *crawlStats) fill(crawl *db.Crawl) {
+	crawl.Discovered = atomic.LoadUint64(&s.discovered)
+	crawl.New = atomic.LoadUint64(&s.new)
+	crawl.Updated = atomic.LoadUint64(&s.updated)
+	crawl.Foreign = atomic.LoadUint64(&s.foreign)
+	crawl.Failed = atomic.LoadUint64(&s.failed)
+}
+
+func (s *crawlStats) logSummary() {
+	log.WithFields(log.Fields{
+		"discovered": atomic.LoadUint64(&s.discovered),
+		"new":        atomic.LoadUint64(&s.new),
+		"updated":    atomic.LoadUint64(&s.updated),
+		"foreign":    atomic.LoadUint64(&s.foreign),
+		"failed":     atomic.LoadUint64(&s.failed),
+	}).Info("crawl summary")
+}
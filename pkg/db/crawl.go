@@ -0,0 +1,110 @@
+package db
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/pkg/errors"
+)
+
+// exit statuses of a crawl
+const (
+	CrawlRunning     = "running"
+	CrawlCompleted   = "completed"
+	CrawlInterrupted = "interrupted"
+)
+
+// Crawl is a session of the crawler, recorded in the crawls table with its parameters and results
+type Crawl struct {
+	ID        int64
+	StartTime time.Time
+	EndTime   time.Time
+	// node_id of the crawler
+	PeerID enode.ID
+	// snapshot of the crawler config, stored as JSON
+	Config interface{}
+
+	Discovered uint64
+	New        uint64
+	Updated    uint64
+	Foreign    uint64
+	Failed     uint64
+
+	Status string
+}
+
+// nullableCrawlID returns nil (NULL in the db) for the observations that don't belong to a recorded crawl
+func nullableCrawlID(id int64) interface{} {
+	if id == 0 {
+		return nil
+	}
+	return id
+}
+
+// nullableTime returns nil (NULL in the db) for the zero time
+func nullableTime(t time.Time) interface{} {
+	if t.IsZero() {
+		return nil
+	}
+	return t
+}
+
+// InsertCrawl records the start of the crawl, setting the ID that its observations reference
+func (d *DBClient) InsertCrawl(crawl *Crawl) error {
+	config, err := json.Marshal(crawl.Config)
+	if err != nil {
+		return errors.Wrap(err, "unable to marshal the crawl config")
+	}
+
+	err = d.psqlPool.QueryRow(
+		d.ctx, `
+			INSERT INTO crawls(
+				start_time,
+				end_time,
+				peer_id,
+				config,
+				status)
+			VALUES($1,$2,$3,$4,$5)
+			RETURNING id
+		`,
+		crawl.StartTime,
+		nullableTime(crawl.EndTime),
+		crawl.PeerID[:],
+		string(config),
+		crawl.Status,
+	).Scan(&crawl.ID)
+	if err != nil {
+		return errors.Wrap(err, "unable to insert crawl")
+	}
+	return nil
+}
+
+// UpdateCrawl records the end of the crawl, along with its results
+func (d *DBClient) UpdateCrawl(crawl *Crawl) error {
+	_, err := d.psqlPool.Exec(
+		d.ctx, `
+			UPDATE crawls SET
+				end_time=$2,
+				discovered=$3,
+				new_nodes=$4,
+				updated_nodes=$5,
+				foreign_nodes=$6,
+				failed_nodes=$7,
+				status=$8
+			WHERE id=$1
+		`,
+		crawl.ID,
+		nullableTime(crawl.EndTime),
+		crawl.Discovered,
+		crawl.New,
+		crawl.Updated,
+		crawl.Foreign,
+		crawl.Failed,
+		crawl.Status,
+	)
+	if err != nil {
+		return errors.Wrap(err, "unable to update crawl")
+	}
+	return nil
+}
@@ -1,66 +1,95 @@
 package db
 
 import (
-	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"time"
 
 	gcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/p2p/enode"
 	"github.com/migalabs/eth-light-crawler/pkg/discv5"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 )
 
-func (d *DBClient) dropEnrTable() error {
-	log.Debugf("droping enrs table in the db")
-
-	_, err := d.psqlPool.Exec(d.ctx, `
-		DROP TABLE enrs;
-	`)
-	return err
-
-}
-
-func (d *DBClient) initEnrDatabase() error {
-	log.Debugf("initializing enrs table in the db")
-
-	// try create the table in the DB
-	_, err := d.psqlPool.Exec(
-		d.ctx, `
-		CREATE TABLE IF NOT EXISTS enrs(
-			id SERIAL,
-			timestamp BIGINT NOT NULL,
-			node_id TEXT NOT NULL,
-			seq BIGINT NOT NULL,
-			ip TEXT NOT NULL,
-			tcp INT,
-			udp INT,
-			pubkey TEXT NOT NULL,
-			fork_digest TEXT,
-			next_fork_version TEXT,
-			attnets TEXT, 
-			attnets_number INT,
-
-			PRIMARY KEY(node_id)	
-		);
-		`,
-	)
-	if err != nil {
-		return errors.Wrap(err, "unable to create table enrs in the db")
+const (
+	enrsTable        = "enrs"
+	foreignEnrsTable = "foreign_enrs"
+)
+
+// nullableIP returns nil (NULL in the db) for empty IPs, instead of their "<nil>" string
+func nullableIP(ip net.IP) interface{} {
+	if len(ip) == 0 {
+		return nil
 	}
+	return ip.String()
+}
+
+// bitString composes the BIT(size) value of an SSZ bitvector, where the i-th bit is the i-th subnet
+// (the bitvector is little-endian, so subnet i is the bit i%8 of the byte i/8).
+// Returns nil (NULL in the db) if the bitvector is shorter than the size
+func bitString(bitvector []byte, size int) interface{} {
+	if len(bitvector)*8 < size {
+		return nil
+	}
+	bits := make([]byte, size)
+	for i := 0; i < size; i++ {
+		bits[i] = '0' + (bitvector[i/8]>>(i%8))&1
+	}
+	return string(bits)
+}
+
+// ForeignEnr wraps the ENRs that don't match the fork digests tracked by the crawler,
+// so that they get persisted in the foreign_enrs table
+type ForeignEnr struct {
+	*discv5.EnrNode
+}
 
+// CrawlerSource is the source of the ENRs discovered by the crawler
+const CrawlerSource = "discv5"
+
+// ImportedEnr wraps the ENRs collected from other sources (i.e. client bootnode lists),
+// so that they get persisted in the enrs table tagged with their source. Since the crawler
+// didn't see them, they don't set the last_seen of the nodes
+type ImportedEnr struct {
+	*discv5.EnrNode
+	Source string
+}
+
+// UpsertEnr queues the upsert of the ENR: it gets inserted in the DB, or it updates the stored one
+// if it has a higher seq number. The last_seen timestamp of the node gets bumped in any case
+func (d *DBClient) UpsertEnr(enr *discv5.EnrNode) error {
+	d.InsertIntoDB(enr)
 	return nil
 }
 
-// Insert ENR in the DB
-// insert into the db if new one, update the data if the ENR has a higher Seq number
-func (d *DBClient) InsertEnr(enr *discv5.EnrNode) error {
-	log.Debug("inserting enr in the db")
+// UpsertForeignEnr queues the upsert of the ENR of a node from a non-tracked fork digest
+func (d *DBClient) UpsertForeignEnr(enr *ForeignEnr) error {
+	d.InsertIntoDB(enr)
+	return nil
+}
 
-	pubBytes := gcrypto.FromECDSAPub(enr.Pubkey)
-	pubkey := hex.EncodeToString(pubBytes)
+// UpsertImportedEnr queues the upsert of an ENR collected from another source
+func (d *DBClient) UpsertImportedEnr(enr *ImportedEnr) error {
+	d.InsertIntoDB(enr)
+	return nil
+}
+
+// upsertEnrQueries composes the upsert of the ENR (tagged with its source) followed by the bump
+// of its last_seen, which is a no-op if the upsert already updated the row. The last_seen is only
+// set if the crawler saw the node
+func upsertEnrQueries(table string, enr *discv5.EnrNode, source string, seen bool) ([]dbQuery, error) {
+	pubkey := gcrypto.FromECDSAPub(enr.Pubkey)
+
+	fields, err := json.Marshal(enr.Fields)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to marshal enr fields")
+	}
 
-	_, err := d.psqlPool.Exec(
-		d.ctx, `
-			INSERT INTO enrs(
+	upsert := newDBQuery(
+		fmt.Sprintf(`
+			INSERT INTO %[1]s(
 				timestamp,
 				node_id,
 				seq,
@@ -71,67 +100,310 @@ func (d *DBClient) InsertEnr(enr *discv5.EnrNode) error {
 				fork_digest,
 				next_fork_version,
 				attnets,
-				attnets_number)
-			VALUES($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11)	
-		`,
-		enr.Timestamp.Unix(),
-		enr.ID.String(),
+				attnets_number,
+				syncnets,
+				syncnets_number,
+				enr,
+				enr_fields,
+				ip6,
+				tcp6,
+				udp6,
+				last_seen,
+				source)
+			VALUES($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17,$18,$19,$20)
+			ON CONFLICT (node_id) DO UPDATE SET
+				timestamp=excluded.timestamp,
+				seq=excluded.seq,
+				ip=excluded.ip,
+				tcp=excluded.tcp,
+				udp=excluded.udp,
+				pubkey=excluded.pubkey,
+				fork_digest=excluded.fork_digest,
+				next_fork_version=excluded.next_fork_version,
+				attnets=excluded.attnets,
+				attnets_number=excluded.attnets_number,
+				syncnets=excluded.syncnets,
+				syncnets_number=excluded.syncnets_number,
+				enr=excluded.enr,
+				enr_fields=excluded.enr_fields,
+				ip6=excluded.ip6,
+				tcp6=excluded.tcp6,
+				udp6=excluded.udp6,
+				source=excluded.source
+			WHERE excluded.seq > %[1]s.seq
+		`, table),
+		enr.Timestamp,
+		enr.ID[:],
 		enr.Seq,
-		enr.IP,
+		nullableIP(enr.IP),
 		enr.TCP,
 		enr.UDP,
 		pubkey,
-		enr.Eth2Data.ForkDigest.String(),
-		enr.Eth2Data.NextForkVersion.String(),
-		hex.EncodeToString(enr.Attnets.Raw[:]),
+		enr.Eth2Data.ForkDigest[:],
+		enr.Eth2Data.NextForkVersion[:],
+		bitString(enr.Attnets.Raw[:], discv5.AttnetsSize),
 		enr.Attnets.NetNumber,
+		bitString(enr.Syncnets.Raw[:], discv5.SyncnetsSize),
+		enr.Syncnets.NetNumber,
+		enr.Enr,
+		fields,
+		nullableIP(enr.IP6),
+		enr.TCP6,
+		enr.UDP6,
+		nullableTime(lastSeenTime(enr, seen)),
+		source,
+	)
+	if !seen {
+		return []dbQuery{upsert}, nil
+	}
+
+	// the stored ENR might be already up to date, keep track of when we last saw it anyway
+	// (only moving it forward, GREATEST ignores the NULL last_seen of the imported nodes)
+	lastSeen := newDBQuery(
+		fmt.Sprintf(`
+			UPDATE %s SET
+				last_seen=GREATEST(last_seen, $2)
+			WHERE node_id=$1
+		`, table),
+		enr.ID[:],
+		enr.Timestamp,
+	)
+
+	return []dbQuery{upsert, lastSeen}, nil
+}
+
+// lastSeenTime returns the time at which the crawler saw the node, or the zero time if it didn't
+func lastSeenTime(enr *discv5.EnrNode, seen bool) time.Time {
+	if !seen {
+		return time.Time{}
+	}
+	return enr.Timestamp
+}
+
+// GetEnrSeqs returns the seq number of the ENRs stored in the enrs table (node_id -> seq),
+// limited to the most recently updated limit nodes (0 = all of them)
+func (d *DBClient) GetEnrSeqs(limit int) (map[enode.ID]uint64, error) {
+	return d.getEnrSeqs(enrsTable, limit)
+}
+
+func (d *DBClient) getEnrSeqs(table string, limit int) (map[enode.ID]uint64, error) {
+	log.Debugf("reading the seq numbers of the %s table", table)
+
+	// LIMIT NULL doesn't limit the rows
+	var rowLimit interface{}
+	if limit > 0 {
+		rowLimit = limit
+	}
+
+	rows, err := d.psqlPool.Query(
+		d.ctx, fmt.Sprintf(`
+			SELECT node_id, seq
+			FROM %s
+			ORDER BY timestamp DESC
+			LIMIT $1
+		`, table),
+		rowLimit,
 	)
 	if err != nil {
-		log.Error(enr)
-		return errors.Wrap(err, "unable to insert enr")
+		return nil, errors.Wrap(err, "unable to read the enr seq numbers")
 	}
+	defer rows.Close()
 
-	return nil
+	seqs := make(map[enode.ID]uint64)
+	for rows.Next() {
+		var nodeID []byte
+		var seq int64
+		if err := rows.Scan(&nodeID, &seq); err != nil {
+			return nil, errors.Wrap(err, "unable to read the enr seq numbers")
+		}
+		var id enode.ID
+		if len(nodeID) != len(id) {
+			log.Warnf("skipping invalid node_id 0x%x in the %s table", nodeID, table)
+			continue
+		}
+		copy(id[:], nodeID)
+		seqs[id] = uint64(seq)
+	}
+	return seqs, rows.Err()
 }
 
-// Update ENR in the DB
-// insert into the db if new one, update the data if the ENR has a higher Seq number
-func (d *DBClient) UpdateEnr(enr *discv5.EnrNode) error {
-	log.Debug("inserting enr in the db")
-
-	pubBytes := gcrypto.FromECDSAPub(enr.Pubkey)
-	pubkey := hex.EncodeToString(pubBytes)
-
-	_, err := d.psqlPool.Exec(
-		d.ctx, `
-			UPDATE enrs SET
-				timestamp=$2,
-				seq=$3,
-				ip=$4,
-				tcp=$5,
-				udp=$6,
-				pubkey=$7,
-				fork_digest=$8,
-				next_fork_version=$9,
-				attnets=$10,
-				attnets_number=$11
-			WHERE node_id=$1
-		`,
-		enr.ID.String(),
-		enr.Timestamp.Unix(),
-		enr.Seq,
-		enr.IP,
-		enr.TCP,
-		enr.UDP,
-		pubkey,
-		enr.Eth2Data.ForkDigest.String(),
-		enr.Eth2Data.NextForkVersion.String(),
-		hex.EncodeToString(enr.Attnets.Raw[:]),
-		enr.Attnets.NetNumber,
+// GetEnr returns the ENR of the node stored in the enrs table
+func (d *DBClient) GetEnr(id enode.ID) (*discv5.EnrNode, error) {
+	enrs, err := d.queryEnrs(`
+		SELECT enr, timestamp
+		FROM enrs
+		WHERE node_id=$1 AND enr IS NOT NULL
+	`, id[:])
+	if err != nil {
+		return nil, err
+	}
+	if len(enrs) == 0 {
+		return nil, ErrNotFound
+	}
+	return enrs[0], nil
+}
+
+// enrFilterClause are the conditions of the EnrFilter over the enrs table, whose params
+// ($1 to $6) are returned by enrFilterArgs. The rows without a raw record (stored before it was persisted)
+// are left out, since the ENRs can't be rebuilt from them (and the other stores don't have them)
+const enrFilterClause = `
+	enr IS NOT NULL
+	AND ($1::BYTEA[] IS NULL OR fork_digest = ANY($1))
+	AND ($2::TIMESTAMPTZ IS NULL OR last_seen >= $2)
+	AND ($3::TIMESTAMPTZ IS NULL OR last_seen <= $3)
+	AND ($4::INET IS NULL OR ip <<= $4 OR ip6 <<= $4)
+	AND ($5::INT IS NULL OR attnets_number >= $5)
+	AND ($6::INT IS NULL OR attnets_number <= $6)
+`
+
+// enrFilterArgs returns the params of the enrFilterClause, where NULL params disable their condition
+func enrFilterArgs(filter *EnrFilter) []interface{} {
+	var forkDigests, since, until, ipNet, minAttnets, maxAttnets interface{}
+	if len(filter.ForkDigests) > 0 {
+		digests := make([][]byte, 0, len(filter.ForkDigests))
+		for i := range filter.ForkDigests {
+			digests = append(digests, filter.ForkDigests[i][:])
+		}
+		forkDigests = digests
+	}
+	if !filter.Since.IsZero() {
+		since = filter.Since
+	}
+	if !filter.Until.IsZero() {
+		until = filter.Until
+	}
+	if filter.IPNet != nil {
+		ipNet = filter.IPNet.String()
+	}
+	if filter.MinAttnets != nil {
+		minAttnets = *filter.MinAttnets
+	}
+	if filter.MaxAttnets != nil {
+		maxAttnets = *filter.MaxAttnets
+	}
+	return []interface{}{forkDigests, since, until, ipNet, minAttnets, maxAttnets}
+}
+
+// GetEnrs returns the ENRs of the enrs table that match the filter, the most recently seen first
+func (d *DBClient) GetEnrs(filter *EnrFilter) ([]*discv5.EnrNode, error) {
+	// LIMIT NULL doesn't limit the rows
+	var limit interface{}
+	if filter.Limit > 0 {
+		limit = filter.Limit
+	}
+
+	return d.queryEnrs(`
+		SELECT enr, timestamp
+		FROM enrs
+		WHERE `+enrFilterClause+`
+		ORDER BY last_seen DESC NULLS LAST
+		LIMIT $7
+	`, append(enrFilterArgs(filter), limit)...)
+}
+
+// GetForkStats counts the nodes of the enrs table that match the filter by fork digest
+func (d *DBClient) GetForkStats(filter *EnrFilter) ([]ForkStats, error) {
+	rows, err := d.psqlPool.Query(d.ctx, `
+		SELECT fork_digest, COUNT(*)
+		FROM enrs
+		WHERE `+enrFilterClause+`
+		GROUP BY fork_digest
+	`, enrFilterArgs(filter)...)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to read the fork stats")
+	}
+	defer rows.Close()
+
+	stats := make([]ForkStats, 0)
+	for rows.Next() {
+		var digest []byte
+		var nodes int
+		if err := rows.Scan(&digest, &nodes); err != nil {
+			return nil, errors.Wrap(err, "unable to read the fork stats")
+		}
+		forkStats := ForkStats{Nodes: nodes}
+		copy(forkStats.ForkDigest[:], digest)
+		stats = append(stats, forkStats)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	sortForkStats(stats)
+	return stats, nil
+}
+
+// GetSubnetStats counts the nodes of the enrs table that match the filter by attestation and sync subnet
+func (d *DBClient) GetSubnetStats(filter *EnrFilter) (*SubnetStats, error) {
+	args := enrFilterArgs(filter)
+	stats := new(SubnetStats)
+
+	err := d.psqlPool.QueryRow(d.ctx, `
+		SELECT COUNT(*)
+		FROM enrs
+		WHERE `+enrFilterClause, args...).Scan(&stats.Nodes)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to count the nodes")
+	}
+	if err := d.countSubnetBits("attnets", stats.Attnets[:], args); err != nil {
+		return nil, err
+	}
+	if err := d.countSubnetBits("syncnets", stats.Syncnets[:], args); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// countSubnetBits counts the filtered nodes with each bit of the given BIT(n) column set
+func (d *DBClient) countSubnetBits(column string, counters []int, args []interface{}) error {
+	rows, err := d.psqlPool.Query(
+		d.ctx, fmt.Sprintf(`
+			SELECT subnet, COUNT(*)
+			FROM enrs
+			CROSS JOIN generate_series(0, %[2]d) AS subnet
+			WHERE %[1]s IS NOT NULL AND get_bit(%[1]s, subnet) = 1 AND `+enrFilterClause+`
+			GROUP BY subnet
+		`, column, len(counters)-1),
+		args...,
 	)
 	if err != nil {
-		log.Error(enr)
-		return errors.Wrap(err, "unable to update enr")
+		return errors.Wrapf(err, "unable to read the %s stats", column)
 	}
-	return nil
+	defer rows.Close()
+
+	for rows.Next() {
+		var subnet, nodes int
+		if err := rows.Scan(&subnet, &nodes); err != nil {
+			return errors.Wrapf(err, "unable to read the %s stats", column)
+		}
+		if subnet >= 0 && subnet < len(counters) {
+			counters[subnet] = nodes
+		}
+	}
+	return rows.Err()
+}
+
+// queryEnrs rebuilds the ENRs (enr, timestamp) returned by the query
+func (d *DBClient) queryEnrs(query string, args ...interface{}) ([]*discv5.EnrNode, error) {
+	rows, err := d.psqlPool.Query(d.ctx, query, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to read the enrs")
+	}
+	defer rows.Close()
+
+	enrs := make([]*discv5.EnrNode, 0)
+	for rows.Next() {
+		var record string
+		var timestamp time.Time
+		if err := rows.Scan(&record, &timestamp); err != nil {
+			return nil, errors.Wrap(err, "unable to read the enrs")
+		}
+		enr, err := discv5.ParseEnr(record)
+		if err != nil {
+			log.Warnf("skipping invalid stored enr %s - %s", record, err.Error())
+			continue
+		}
+		enr.Timestamp = timestamp
+		enrs = append(enrs, enr)
+	}
+	return enrs, rows.Err()
 }
@@ -0,0 +1,71 @@
+package db
+
+import (
+	gcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/migalabs/eth-light-crawler/pkg/discv5"
+)
+
+// EnrHistory wraps an observation of an ENR that has to be appended to the enr_history table,
+// where each (node_id, seq) version of a record is kept once per crawl
+type EnrHistory struct {
+	*discv5.EnrNode
+	// crawl in which the version was observed (0 = none)
+	CrawlID int64
+}
+
+// InsertEnrHistory queues a new version of the ENR to be appended to the history,
+// or refreshes the last_seen of the version if it was already tracked
+func (d *DBClient) InsertEnrHistory(enr *EnrHistory) error {
+	d.InsertIntoDB(enr)
+	return nil
+}
+
+func insertEnrHistoryQuery(enr *EnrHistory) dbQuery {
+	pubkey := gcrypto.FromECDSAPub(enr.Pubkey)
+
+	return newDBQuery(
+		`
+			INSERT INTO enr_history(
+				node_id,
+				seq,
+				first_seen,
+				last_seen,
+				ip,
+				tcp,
+				udp,
+				pubkey,
+				fork_digest,
+				next_fork_version,
+				attnets,
+				attnets_number,
+				syncnets,
+				syncnets_number,
+				enr,
+				ip6,
+				tcp6,
+				udp6,
+				crawl_id)
+			VALUES($1,$2,$3,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17,$18)
+			ON CONFLICT (node_id, seq, (COALESCE(crawl_id, 0))) DO UPDATE SET
+				last_seen = excluded.last_seen
+		`,
+		enr.ID[:],
+		enr.Seq,
+		enr.Timestamp,
+		nullableIP(enr.IP),
+		enr.TCP,
+		enr.UDP,
+		pubkey,
+		enr.Eth2Data.ForkDigest[:],
+		enr.Eth2Data.NextForkVersion[:],
+		bitString(enr.Attnets.Raw[:], discv5.AttnetsSize),
+		enr.Attnets.NetNumber,
+		bitString(enr.Syncnets.Raw[:], discv5.SyncnetsSize),
+		enr.Syncnets.NetNumber,
+		enr.Enr,
+		nullableIP(enr.IP6),
+		enr.TCP6,
+		enr.UDP6,
+		nullableCrawlID(enr.CrawlID),
+	)
+}
@@ -0,0 +1,88 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/migalabs/eth-light-crawler/pkg/discv5"
+)
+
+// getBit reads the bit of the BIT(n) string as postgres' get_bit does (0 = leftmost bit)
+func getBit(bits string, i int) int {
+	return int(bits[i] - '0')
+}
+
+// subnetBit reads the bit of the subnet from the little-endian SSZ bitvector
+func subnetBit(bitvector []byte, subnet int) int {
+	return int(bitvector[subnet/8]>>(subnet%8)) & 1
+}
+
+func TestBitString(t *testing.T) {
+	tests := []struct {
+		name      string
+		bitvector []byte
+		size      int
+		expected  interface{}
+	}{
+		{
+			name:      "empty attnets",
+			bitvector: make([]byte, 8),
+			size:      discv5.AttnetsSize,
+			expected:  "0000000000000000000000000000000000000000000000000000000000000000",
+		},
+		{
+			name:      "first and last attnets",
+			bitvector: []byte{0x01, 0, 0, 0, 0, 0, 0, 0x80},
+			size:      discv5.AttnetsSize,
+			expected:  "1000000000000000000000000000000000000000000000000000000000000001",
+		},
+		{
+			name:      "little-endian bytes",
+			bitvector: []byte{0x06, 0x01, 0, 0, 0, 0, 0, 0},
+			size:      discv5.AttnetsSize,
+			expected:  "0110000010000000000000000000000000000000000000000000000000000000",
+		},
+		{
+			name:      "syncnets",
+			bitvector: []byte{0x0a},
+			size:      discv5.SyncnetsSize,
+			expected:  "0101",
+		},
+		{
+			name:      "syncnets ignore the padding bits",
+			bitvector: []byte{0xf1},
+			size:      discv5.SyncnetsSize,
+			expected:  "1000",
+		},
+		{
+			name:      "too short",
+			bitvector: []byte{0x01},
+			size:      discv5.AttnetsSize,
+			expected:  nil,
+		},
+		{
+			name:      "missing",
+			bitvector: nil,
+			size:      discv5.SyncnetsSize,
+			expected:  nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			bits := bitString(test.bitvector, test.size)
+			if bits != test.expected {
+				t.Fatalf("bitString returned %v, expected %v", bits, test.expected)
+			}
+			if bits == nil {
+				return
+			}
+
+			// the subnets read back from the BIT(n) value must be the ones of the bitvector
+			for subnet := 0; subnet < test.size; subnet++ {
+				if bit, expected := getBit(bits.(string), subnet), subnetBit(test.bitvector, subnet); bit != expected {
+					t.Errorf("subnet %d: bit %d in the BIT(n) value, %d in the bitvector", subnet, bit, expected)
+				}
+			}
+		})
+	}
+}
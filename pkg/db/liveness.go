@@ -0,0 +1,64 @@
+package db
+
+import (
+	"github.com/migalabs/eth-light-crawler/pkg/discv5"
+)
+
+// PingResult wraps the outcome of a liveness probe, along with the crawl that sent it
+type PingResult struct {
+	*discv5.PingResult
+	// crawl that probed the node (0 = none)
+	CrawlID int64
+}
+
+// UpsertPingResult queues the outcome of a liveness probe to be aggregated into the node's liveness row
+func (d *DBClient) UpsertPingResult(result *PingResult) error {
+	d.InsertIntoDB(result)
+	return nil
+}
+
+func upsertPingResultQuery(result *PingResult) dbQuery {
+	var successes, failures int
+	var lastSeenAlive, rtt interface{}
+	if result.Err != nil {
+		failures = 1
+	} else {
+		successes = 1
+		lastSeenAlive = result.Timestamp
+		rtt = float64(result.RTT.Microseconds()) / 1000
+	}
+
+	return newDBQuery(
+		`
+			INSERT INTO node_liveness(
+				node_id,
+				last_ping,
+				last_seen_alive,
+				rtt_ms,
+				ping_successes,
+				ping_failures,
+				consecutive_failures,
+				online,
+				crawl_id)
+			VALUES($1,$2,$3,$4,$5,$6,$7,$8,$9)
+			ON CONFLICT (node_id) DO UPDATE SET
+				last_ping = excluded.last_ping,
+				last_seen_alive = COALESCE(excluded.last_seen_alive, node_liveness.last_seen_alive),
+				rtt_ms = COALESCE(excluded.rtt_ms, node_liveness.rtt_ms),
+				ping_successes = node_liveness.ping_successes + excluded.ping_successes,
+				ping_failures = node_liveness.ping_failures + excluded.ping_failures,
+				consecutive_failures = excluded.consecutive_failures,
+				online = excluded.online,
+				crawl_id = excluded.crawl_id
+		`,
+		result.NodeID[:],
+		result.Timestamp,
+		lastSeenAlive,
+		rtt,
+		successes,
+		failures,
+		result.ConsecutiveFailures,
+		!result.Offline,
+		nullableCrawlID(result.CrawlID),
+	)
+}
@@ -0,0 +1,230 @@
+package db
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/migalabs/eth-light-crawler/pkg/discv5"
+)
+
+type storedEnr struct {
+	enr      *discv5.EnrNode
+	lastSeen time.Time
+	source   string
+}
+
+type enrVersion struct {
+	id      enode.ID
+	seq     uint64
+	crawlID int64
+}
+
+type storedVersion struct {
+	enr       *discv5.EnrNode
+	firstSeen time.Time
+	lastSeen  time.Time
+}
+
+// nodeLiveness aggregates the liveness probes of a node, as the node_liveness table does
+type nodeLiveness struct {
+	lastPing            time.Time
+	lastSeenAlive       time.Time
+	rtt                 time.Duration
+	successes           int
+	failures            int
+	consecutiveFailures int
+	online              bool
+}
+
+// MemoryStore keeps the crawled data in memory (i.e. for tests or short crawls whose results
+// don't need to be kept). It is safe for concurrent use
+type MemoryStore struct {
+	m             sync.RWMutex
+	enrs          map[enode.ID]*storedEnr
+	foreignEnrs   map[enode.ID]*storedEnr
+	history       map[enrVersion]*storedVersion
+	routingTables []*RoutingTable
+	liveness      map[enode.ID]*nodeLiveness
+	crawls        []Crawl
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		enrs:          make(map[enode.ID]*storedEnr),
+		foreignEnrs:   make(map[enode.ID]*storedEnr),
+		history:       make(map[enrVersion]*storedVersion),
+		routingTables: make([]*RoutingTable, 0),
+		liveness:      make(map[enode.ID]*nodeLiveness),
+	}
+}
+
+func (s *MemoryStore) UpsertEnr(enr *discv5.EnrNode) error {
+	s.m.Lock()
+	defer s.m.Unlock()
+	upsertStoredEnr(s.enrs, enr, CrawlerSource, true)
+	return nil
+}
+
+func (s *MemoryStore) UpsertForeignEnr(enr *ForeignEnr) error {
+	s.m.Lock()
+	defer s.m.Unlock()
+	upsertStoredEnr(s.foreignEnrs, enr.EnrNode, CrawlerSource, true)
+	return nil
+}
+
+func (s *MemoryStore) UpsertImportedEnr(enr *ImportedEnr) error {
+	s.m.Lock()
+	defer s.m.Unlock()
+	upsertStoredEnr(s.enrs, enr.EnrNode, enr.Source, false)
+	return nil
+}
+
+// upsertStoredEnr keeps the ENR with the highest seq, only bumping the last time that the node was seen
+// if the crawler saw it (the zero time = never)
+func upsertStoredEnr(enrs map[enode.ID]*storedEnr, enr *discv5.EnrNode, source string, seen bool) {
+	lastSeen := lastSeenTime(enr, seen)
+	stored, ok := enrs[enr.ID]
+	if !ok {
+		enrs[enr.ID] = &storedEnr{enr: enr, lastSeen: lastSeen, source: source}
+		return
+	}
+	if enr.Seq > stored.enr.Seq {
+		stored.enr = enr
+		stored.source = source
+	}
+	if lastSeen.After(stored.lastSeen) {
+		stored.lastSeen = lastSeen
+	}
+}
+
+func (s *MemoryStore) InsertEnrHistory(enr *EnrHistory) error {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	version := enrVersion{id: enr.ID, seq: enr.Seq, crawlID: enr.CrawlID}
+	stored, ok := s.history[version]
+	if !ok {
+		s.history[version] = &storedVersion{
+			enr:       enr.EnrNode,
+			firstSeen: enr.Timestamp,
+			lastSeen:  enr.Timestamp,
+		}
+		return nil
+	}
+	stored.lastSeen = enr.Timestamp
+	return nil
+}
+
+func (s *MemoryStore) InsertRoutingTable(rt *RoutingTable) error {
+	s.m.Lock()
+	defer s.m.Unlock()
+	s.routingTables = append(s.routingTables, rt)
+	return nil
+}
+
+func (s *MemoryStore) UpsertPingResult(result *PingResult) error {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	liveness, ok := s.liveness[result.NodeID]
+	if !ok {
+		liveness = new(nodeLiveness)
+		s.liveness[result.NodeID] = liveness
+	}
+	liveness.lastPing = result.Timestamp
+	if result.Err != nil {
+		liveness.failures++
+	} else {
+		liveness.successes++
+		liveness.lastSeenAlive = result.Timestamp
+		liveness.rtt = result.RTT
+	}
+	liveness.consecutiveFailures = result.ConsecutiveFailures
+	liveness.online = !result.Offline
+	return nil
+}
+
+func (s *MemoryStore) InsertCrawl(crawl *Crawl) error {
+	s.m.Lock()
+	defer s.m.Unlock()
+	crawl.ID = int64(len(s.crawls) + 1)
+	s.crawls = append(s.crawls, *crawl)
+	return nil
+}
+
+func (s *MemoryStore) UpdateCrawl(crawl *Crawl) error {
+	s.m.Lock()
+	defer s.m.Unlock()
+	if crawl.ID < 1 || crawl.ID > int64(len(s.crawls)) {
+		return ErrNotFound
+	}
+	s.crawls[crawl.ID-1] = *crawl
+	return nil
+}
+
+func (s *MemoryStore) GetEnrSeqs(limit int) (map[enode.ID]uint64, error) {
+	s.m.RLock()
+	defer s.m.RUnlock()
+
+	stored := s.sortedEnrs(&EnrFilter{Limit: limit})
+	seqs := make(map[enode.ID]uint64, len(stored))
+	for _, enr := range stored {
+		seqs[enr.ID] = enr.Seq
+	}
+	return seqs, nil
+}
+
+func (s *MemoryStore) GetEnr(id enode.ID) (*discv5.EnrNode, error) {
+	s.m.RLock()
+	defer s.m.RUnlock()
+
+	stored, ok := s.enrs[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return stored.enr, nil
+}
+
+func (s *MemoryStore) GetEnrs(filter *EnrFilter) ([]*discv5.EnrNode, error) {
+	s.m.RLock()
+	defer s.m.RUnlock()
+	return s.sortedEnrs(filter), nil
+}
+
+func (s *MemoryStore) GetForkStats(filter *EnrFilter) ([]ForkStats, error) {
+	s.m.RLock()
+	defer s.m.RUnlock()
+	return countForks(s.sortedEnrs(filter.unlimited())), nil
+}
+
+func (s *MemoryStore) GetSubnetStats(filter *EnrFilter) (*SubnetStats, error) {
+	s.m.RLock()
+	defer s.m.RUnlock()
+	return countSubnets(s.sortedEnrs(filter.unlimited())), nil
+}
+
+// sortedEnrs returns the ENRs that match the filter, the most recently seen first
+func (s *MemoryStore) sortedEnrs(filter *EnrFilter) []*discv5.EnrNode {
+	matching := make([]*storedEnr, 0, len(s.enrs))
+	for _, stored := range s.enrs {
+		if filter.matches(stored.enr, stored.lastSeen) {
+			matching = append(matching, stored)
+		}
+	}
+	sort.Slice(matching, func(i, j int) bool {
+		return matching[i].lastSeen.After(matching[j].lastSeen)
+	})
+	if filter.Limit > 0 && len(matching) > filter.Limit {
+		matching = matching[:filter.Limit]
+	}
+
+	enrs := make([]*discv5.EnrNode, 0, len(matching))
+	for _, stored := range matching {
+		enrs = append(enrs, stored.enr)
+	}
+	return enrs
+}
+
+func (s *MemoryStore) Close() {}
@@ -0,0 +1,35 @@
+package db
+
+import (
+	"github.com/migalabs/eth-light-crawler/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	persistQueueDepth = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "db",
+		Name:      "persist_queue_depth",
+		Help:      "Items waiting in the persist queue to be written to the db.",
+	})
+	persistedItems = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "db",
+		Name:      "persisted_items_total",
+		Help:      "Items written to the db.",
+	})
+	writeLatency = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "db",
+		Name:      "write_latency_seconds",
+		Help:      "Latency of the writes to the db (a whole batch for postgres, a single item for sqlite).",
+		Buckets:   prometheus.ExponentialBuckets(0.001, 2, 14),
+	})
+	writeErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "db",
+		Name:      "write_errors_total",
+		Help:      "Failed writes to the db, by operation.",
+	}, []string{"operation"})
+)
@@ -0,0 +1,320 @@
+package db
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// the migrations are numbered SQL files named <version>_<name>.<up|down>.sql
+//
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+var migrationFileRegexp = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// key of the advisory lock that serializes the migrations of concurrent crawlers
+const migrationLockKey = 5_201_020
+
+// Migration is a numbered change of the DB schema
+type Migration struct {
+	Version int
+	Name    string
+	up      string
+	down    string
+}
+
+// MigrationStatus tells whether (and when) a migration was applied to the DB
+type MigrationStatus struct {
+	Migration
+	Applied   bool
+	AppliedAt time.Time
+}
+
+// Migrator applies the embedded migrations, keeping track of the applied ones in the schema_version table
+type Migrator struct {
+	ctx        context.Context
+	psqlPool   *pgxpool.Pool
+	ownsPool   bool
+	migrations []Migration
+}
+
+// NewMigrator connects to the DB at the given endpoint to manage its schema
+func NewMigrator(ctx context.Context, loginStr string) (*Migrator, error) {
+	if len(loginStr) == 0 {
+		return nil, errors.New("empty db-endpoint provided")
+	}
+	if !strings.HasPrefix(loginStr, PostgresPrefix) && !strings.HasPrefix(loginStr, PostgresqlPrefix) {
+		return nil, errors.New("migrations are only supported by the postgres store")
+	}
+	pPool, err := pgxpool.Connect(ctx, loginStr)
+	if err != nil {
+		return nil, err
+	}
+	m, err := newMigrator(ctx, pPool)
+	if err != nil {
+		pPool.Close()
+		return nil, err
+	}
+	m.ownsPool = true
+	return m, nil
+}
+
+func newMigrator(ctx context.Context, pPool *pgxpool.Pool) (*Migrator, error) {
+	migrations, err := loadMigrations()
+	if err != nil {
+		return nil, err
+	}
+	m := &Migrator{
+		ctx:        ctx,
+		psqlPool:   pPool,
+		migrations: migrations,
+	}
+	_, err = pPool.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_version(
+			version INT NOT NULL,
+			name TEXT NOT NULL,
+			applied_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+			PRIMARY KEY(version)
+		);
+	`)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to create the schema_version table")
+	}
+	return m, nil
+}
+
+// loadMigrations reads the embedded migrations sorted by version
+func loadMigrations() ([]Migration, error) {
+	return readMigrations(migrationFiles, "migrations")
+}
+
+// readMigrations reads the migration files of the given directory sorted by version
+func readMigrations(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to read the migrations")
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		match := migrationFileRegexp.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, errors.Errorf("invalid migration file name %s", entry.Name())
+		}
+		version, _ := strconv.Atoi(match[1])
+		content, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to read migration %s", entry.Name())
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		} else if migration.Name != match[2] {
+			return nil, errors.Errorf("migration %d has two different names (%s, %s)", version, migration.Name, match[2])
+		}
+		if match[3] == "up" {
+			migration.up = string(content)
+		} else {
+			migration.down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.up == "" || migration.down == "" {
+			return nil, errors.Errorf("migration %d is missing its up or down file", migration.Version)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	for i, migration := range migrations {
+		if migration.Version != i+1 {
+			return nil, errors.Errorf("migrations must be numbered consecutively from 1, found %d at position %d", migration.Version, i+1)
+		}
+	}
+	return migrations, nil
+}
+
+// LatestVersion returns the version of the newest embedded migration
+func (m *Migrator) LatestVersion() int {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// Version returns the current version of the DB schema (0 = no migration applied)
+func (m *Migrator) Version() (int, error) {
+	return currentVersion(m.ctx, m.psqlPool)
+}
+
+type querier interface {
+	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
+}
+
+func currentVersion(ctx context.Context, q querier) (int, error) {
+	var version int
+	err := q.QueryRow(ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_version`).Scan(&version)
+	if err != nil {
+		return 0, errors.Wrap(err, "unable to read the schema version")
+	}
+	return version, nil
+}
+
+// CheckVersion returns an error if the DB schema is newer than the one supported by this build
+func (m *Migrator) CheckVersion() error {
+	version, err := m.Version()
+	if err != nil {
+		return err
+	}
+	if version > m.LatestVersion() {
+		return errors.Errorf("db schema version %d is newer than the latest supported one (%d), please upgrade the crawler", version, m.LatestVersion())
+	}
+	return nil
+}
+
+// Up applies all the pending migrations, returning the number of applied ones
+func (m *Migrator) Up() (int, error) {
+	if err := m.CheckVersion(); err != nil {
+		return 0, err
+	}
+	applied := 0
+	for _, migration := range m.migrations {
+		ok, err := m.apply(migration, true)
+		if err != nil {
+			return applied, err
+		}
+		if ok {
+			applied++
+		}
+	}
+	return applied, nil
+}
+
+// Down reverts the given number of applied migrations, the newest first (0 = all of them)
+func (m *Migrator) Down(steps int) (int, error) {
+	if err := m.CheckVersion(); err != nil {
+		return 0, err
+	}
+	reverted := 0
+	for i := len(m.migrations) - 1; i >= 0; i-- {
+		if steps > 0 && reverted >= steps {
+			break
+		}
+		ok, err := m.apply(m.migrations[i], false)
+		if err != nil {
+			return reverted, err
+		}
+		if ok {
+			reverted++
+		}
+	}
+	return reverted, nil
+}
+
+// apply runs the up (or down) script of the migration in a transaction, if it wasn't already (or isn't) applied
+func (m *Migrator) apply(migration Migration, up bool) (bool, error) {
+	direction, script := "up", migration.up
+	if !up {
+		direction, script = "down", migration.down
+	}
+
+	tx, err := m.psqlPool.Begin(m.ctx)
+	if err != nil {
+		return false, errors.Wrap(err, "unable to begin the migration transaction")
+	}
+	defer tx.Rollback(m.ctx)
+
+	// other crawlers wait until the migration is done
+	_, err = tx.Exec(m.ctx, `SELECT pg_advisory_xact_lock($1)`, migrationLockKey)
+	if err != nil {
+		return false, errors.Wrap(err, "unable to lock the schema_version table")
+	}
+	version, err := currentVersion(m.ctx, tx)
+	if err != nil {
+		return false, err
+	}
+	if (up && version >= migration.Version) || (!up && version != migration.Version) {
+		return false, nil
+	}
+
+	log.Infof("applying migration %d_%s (%s)", migration.Version, migration.Name, direction)
+	_, err = tx.Exec(m.ctx, script)
+	if err != nil {
+		return false, errors.Wrapf(err, "unable to apply migration %d_%s (%s)", migration.Version, migration.Name, direction)
+	}
+	if up {
+		_, err = tx.Exec(m.ctx, `INSERT INTO schema_version(version, name) VALUES($1,$2)`, migration.Version, migration.Name)
+	} else {
+		_, err = tx.Exec(m.ctx, `DELETE FROM schema_version WHERE version=$1`, migration.Version)
+	}
+	if err != nil {
+		return false, errors.Wrap(err, "unable to update the schema_version table")
+	}
+	return true, tx.Commit(m.ctx)
+}
+
+// Status lists the embedded migrations and whether they were applied to the DB
+func (m *Migrator) Status() ([]MigrationStatus, error) {
+	rows, err := m.psqlPool.Query(m.ctx, `SELECT version, applied_at FROM schema_version`)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to read the schema_version table")
+	}
+	defer rows.Close()
+
+	appliedAt := make(map[int]time.Time)
+	for rows.Next() {
+		var version int
+		var t time.Time
+		if err := rows.Scan(&version, &t); err != nil {
+			return nil, errors.Wrap(err, "unable to read the schema_version table")
+		}
+		appliedAt[version] = t
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	status := make([]MigrationStatus, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		t, ok := appliedAt[migration.Version]
+		status = append(status, MigrationStatus{
+			Migration: migration,
+			Applied:   ok,
+			AppliedAt: t,
+		})
+	}
+	return status, nil
+}
+
+func (s MigrationStatus) String() string {
+	if !s.Applied {
+		return fmt.Sprintf("%04d_%s\tpending", s.Version, s.Name)
+	}
+	return fmt.Sprintf("%04d_%s\tapplied at %s", s.Version, s.Name, s.AppliedAt.Format(time.RFC3339))
+}
+
+// Close closes the connection with the DB (only if the migrator opened it)
+func (m *Migrator) Close() {
+	if m.ownsPool {
+		m.psqlPool.Close()
+	}
+}
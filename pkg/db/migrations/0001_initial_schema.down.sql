@@ -0,0 +1 @@
+DROP TABLE IF EXISTS enrs, foreign_enrs, enr_history, routing_tables, node_liveness;
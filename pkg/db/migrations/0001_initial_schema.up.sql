@@ -0,0 +1,126 @@
+-- initial schema of the crawler, it also adopts the tables created by the versions of the
+-- crawler previous to the migrations (adding the columns that they could be missing)
+
+CREATE TABLE IF NOT EXISTS enrs(
+	id SERIAL,
+	timestamp BIGINT NOT NULL,
+	node_id TEXT NOT NULL,
+	seq BIGINT NOT NULL,
+	ip TEXT NOT NULL,
+	tcp INT,
+	udp INT,
+	ip6 TEXT,
+	tcp6 INT,
+	udp6 INT,
+	pubkey TEXT NOT NULL,
+	fork_digest TEXT,
+	next_fork_version TEXT,
+	attnets TEXT,
+	attnets_number INT,
+	syncnets TEXT,
+	syncnets_number INT,
+	enr TEXT,
+	enr_fields JSONB,
+	last_seen BIGINT,
+
+	PRIMARY KEY(node_id)
+);
+
+ALTER TABLE enrs
+	ADD COLUMN IF NOT EXISTS ip6 TEXT,
+	ADD COLUMN IF NOT EXISTS tcp6 INT,
+	ADD COLUMN IF NOT EXISTS udp6 INT,
+	ADD COLUMN IF NOT EXISTS syncnets TEXT,
+	ADD COLUMN IF NOT EXISTS syncnets_number INT,
+	ADD COLUMN IF NOT EXISTS enr TEXT,
+	ADD COLUMN IF NOT EXISTS enr_fields JSONB,
+	ADD COLUMN IF NOT EXISTS last_seen BIGINT;
+
+CREATE TABLE IF NOT EXISTS foreign_enrs(
+	id SERIAL,
+	timestamp BIGINT NOT NULL,
+	node_id TEXT NOT NULL,
+	seq BIGINT NOT NULL,
+	ip TEXT NOT NULL,
+	tcp INT,
+	udp INT,
+	ip6 TEXT,
+	tcp6 INT,
+	udp6 INT,
+	pubkey TEXT NOT NULL,
+	fork_digest TEXT,
+	next_fork_version TEXT,
+	attnets TEXT,
+	attnets_number INT,
+	syncnets TEXT,
+	syncnets_number INT,
+	enr TEXT,
+	enr_fields JSONB,
+	last_seen BIGINT,
+
+	PRIMARY KEY(node_id)
+);
+
+ALTER TABLE foreign_enrs
+	ADD COLUMN IF NOT EXISTS ip6 TEXT,
+	ADD COLUMN IF NOT EXISTS tcp6 INT,
+	ADD COLUMN IF NOT EXISTS udp6 INT,
+	ADD COLUMN IF NOT EXISTS syncnets TEXT,
+	ADD COLUMN IF NOT EXISTS syncnets_number INT,
+	ADD COLUMN IF NOT EXISTS enr TEXT,
+	ADD COLUMN IF NOT EXISTS enr_fields JSONB,
+	ADD COLUMN IF NOT EXISTS last_seen BIGINT;
+
+CREATE TABLE IF NOT EXISTS enr_history(
+	node_id TEXT NOT NULL,
+	seq BIGINT NOT NULL,
+	first_seen BIGINT NOT NULL,
+	last_seen BIGINT NOT NULL,
+	ip TEXT NOT NULL,
+	tcp INT,
+	udp INT,
+	ip6 TEXT,
+	tcp6 INT,
+	udp6 INT,
+	pubkey TEXT NOT NULL,
+	fork_digest TEXT,
+	next_fork_version TEXT,
+	attnets TEXT,
+	attnets_number INT,
+	syncnets TEXT,
+	syncnets_number INT,
+	enr TEXT NOT NULL,
+
+	PRIMARY KEY(node_id, seq)
+);
+
+ALTER TABLE enr_history
+	ADD COLUMN IF NOT EXISTS ip6 TEXT,
+	ADD COLUMN IF NOT EXISTS tcp6 INT,
+	ADD COLUMN IF NOT EXISTS udp6 INT,
+	ADD COLUMN IF NOT EXISTS syncnets TEXT,
+	ADD COLUMN IF NOT EXISTS syncnets_number INT;
+
+CREATE TABLE IF NOT EXISTS routing_tables(
+	id SERIAL,
+	timestamp BIGINT NOT NULL,
+	node_id TEXT NOT NULL,
+	neighbors TEXT[] NOT NULL,
+	neighbors_number INT NOT NULL,
+	error TEXT,
+
+	PRIMARY KEY(id)
+);
+
+CREATE TABLE IF NOT EXISTS node_liveness(
+	node_id TEXT NOT NULL,
+	last_ping BIGINT NOT NULL,
+	last_seen_alive BIGINT,
+	rtt_ms DOUBLE PRECISION,
+	ping_successes INT NOT NULL,
+	ping_failures INT NOT NULL,
+	consecutive_failures INT NOT NULL,
+	online BOOLEAN NOT NULL,
+
+	PRIMARY KEY(node_id)
+);
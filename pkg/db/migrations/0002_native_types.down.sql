@@ -0,0 +1,74 @@
+-- back to the TEXT/BIGINT encodings of the initial schema
+
+CREATE FUNCTION bits_to_ssz_hex(bits BIT VARYING) RETURNS TEXT AS $$
+DECLARE
+	raw BYTEA;
+BEGIN
+	IF bits IS NULL THEN
+		RETURN '';
+	END IF;
+	raw := decode(repeat('00', (length(bits) + 7) / 8), 'hex');
+	FOR i IN 0..length(bits)-1 LOOP
+		IF get_bit(bits, i) = 1 THEN
+			raw := set_byte(raw, i / 8, get_byte(raw, i / 8) | (1 << (i % 8)));
+		END IF;
+	END LOOP;
+	RETURN encode(raw, 'hex');
+END
+$$ LANGUAGE plpgsql IMMUTABLE;
+
+CREATE FUNCTION bytea_array_to_hex(raws BYTEA[]) RETURNS TEXT[] AS $$
+	SELECT COALESCE(array_agg(encode(r, 'hex') ORDER BY n), '{}') FROM unnest(raws) WITH ORDINALITY AS t(r, n);
+$$ LANGUAGE SQL IMMUTABLE;
+
+ALTER TABLE enrs
+	ALTER COLUMN timestamp TYPE BIGINT USING EXTRACT(EPOCH FROM timestamp)::BIGINT,
+	ALTER COLUMN last_seen TYPE BIGINT USING EXTRACT(EPOCH FROM last_seen)::BIGINT,
+	ALTER COLUMN node_id TYPE TEXT USING encode(node_id, 'hex'),
+	ALTER COLUMN ip TYPE TEXT USING COALESCE(host(ip), ''),
+	ALTER COLUMN ip6 TYPE TEXT USING host(ip6),
+	ALTER COLUMN pubkey TYPE TEXT USING encode(pubkey, 'hex'),
+	ALTER COLUMN fork_digest TYPE TEXT USING '0x' || encode(fork_digest, 'hex'),
+	ALTER COLUMN next_fork_version TYPE TEXT USING '0x' || encode(next_fork_version, 'hex'),
+	ALTER COLUMN attnets TYPE TEXT USING bits_to_ssz_hex(attnets),
+	ALTER COLUMN syncnets TYPE TEXT USING bits_to_ssz_hex(syncnets),
+	ALTER COLUMN ip SET NOT NULL;
+
+ALTER TABLE foreign_enrs
+	ALTER COLUMN timestamp TYPE BIGINT USING EXTRACT(EPOCH FROM timestamp)::BIGINT,
+	ALTER COLUMN last_seen TYPE BIGINT USING EXTRACT(EPOCH FROM last_seen)::BIGINT,
+	ALTER COLUMN node_id TYPE TEXT USING encode(node_id, 'hex'),
+	ALTER COLUMN ip TYPE TEXT USING COALESCE(host(ip), ''),
+	ALTER COLUMN ip6 TYPE TEXT USING host(ip6),
+	ALTER COLUMN pubkey TYPE TEXT USING encode(pubkey, 'hex'),
+	ALTER COLUMN fork_digest TYPE TEXT USING '0x' || encode(fork_digest, 'hex'),
+	ALTER COLUMN next_fork_version TYPE TEXT USING '0x' || encode(next_fork_version, 'hex'),
+	ALTER COLUMN attnets TYPE TEXT USING bits_to_ssz_hex(attnets),
+	ALTER COLUMN syncnets TYPE TEXT USING bits_to_ssz_hex(syncnets),
+	ALTER COLUMN ip SET NOT NULL;
+
+ALTER TABLE enr_history
+	ALTER COLUMN first_seen TYPE BIGINT USING EXTRACT(EPOCH FROM first_seen)::BIGINT,
+	ALTER COLUMN last_seen TYPE BIGINT USING EXTRACT(EPOCH FROM last_seen)::BIGINT,
+	ALTER COLUMN node_id TYPE TEXT USING encode(node_id, 'hex'),
+	ALTER COLUMN ip TYPE TEXT USING COALESCE(host(ip), ''),
+	ALTER COLUMN ip6 TYPE TEXT USING host(ip6),
+	ALTER COLUMN pubkey TYPE TEXT USING encode(pubkey, 'hex'),
+	ALTER COLUMN fork_digest TYPE TEXT USING '0x' || encode(fork_digest, 'hex'),
+	ALTER COLUMN next_fork_version TYPE TEXT USING '0x' || encode(next_fork_version, 'hex'),
+	ALTER COLUMN attnets TYPE TEXT USING bits_to_ssz_hex(attnets),
+	ALTER COLUMN syncnets TYPE TEXT USING bits_to_ssz_hex(syncnets),
+	ALTER COLUMN ip SET NOT NULL;
+
+ALTER TABLE routing_tables
+	ALTER COLUMN timestamp TYPE BIGINT USING EXTRACT(EPOCH FROM timestamp)::BIGINT,
+	ALTER COLUMN node_id TYPE TEXT USING encode(node_id, 'hex'),
+	ALTER COLUMN neighbors TYPE TEXT[] USING bytea_array_to_hex(neighbors);
+
+ALTER TABLE node_liveness
+	ALTER COLUMN last_ping TYPE BIGINT USING EXTRACT(EPOCH FROM last_ping)::BIGINT,
+	ALTER COLUMN last_seen_alive TYPE BIGINT USING EXTRACT(EPOCH FROM last_seen_alive)::BIGINT,
+	ALTER COLUMN node_id TYPE TEXT USING encode(node_id, 'hex');
+
+DROP FUNCTION bits_to_ssz_hex(BIT VARYING);
+DROP FUNCTION bytea_array_to_hex(BYTEA[]);
@@ -0,0 +1,81 @@
+-- replace the TEXT/BIGINT encodings with the native postgres types:
+-- bytea for ids, keys and digests, inet for IPs, timestamptz for timestamps,
+-- and bit strings for the subnet bitvectors (where the i-th bit is the i-th subnet)
+
+-- SSZ bitvectors are stored little-endian (subnet i is the bit i%8 of the byte i/8)
+CREATE FUNCTION ssz_hex_to_bits(hexstr TEXT, size INT) RETURNS BIT VARYING AS $$
+DECLARE
+	raw BYTEA;
+	bits TEXT := '';
+BEGIN
+	IF hexstr IS NULL OR length(hexstr) < 2 * ((size + 7) / 8) THEN
+		RETURN NULL;
+	END IF;
+	raw := decode(hexstr, 'hex');
+	FOR i IN 0..size-1 LOOP
+		bits := bits || ((get_byte(raw, i / 8) >> (i % 8)) & 1)::TEXT;
+	END LOOP;
+	RETURN bits::BIT VARYING;
+END
+$$ LANGUAGE plpgsql IMMUTABLE;
+
+CREATE FUNCTION text_to_ip(ip TEXT) RETURNS INET AS $$
+	SELECT NULLIF(NULLIF(ip, ''), '<nil>')::INET;
+$$ LANGUAGE SQL IMMUTABLE;
+
+CREATE FUNCTION hex_array_to_bytea(hexes TEXT[]) RETURNS BYTEA[] AS $$
+	SELECT COALESCE(array_agg(decode(h, 'hex') ORDER BY n), '{}') FROM unnest(hexes) WITH ORDINALITY AS t(h, n);
+$$ LANGUAGE SQL IMMUTABLE;
+
+ALTER TABLE enrs
+	ALTER COLUMN ip DROP NOT NULL,
+	ALTER COLUMN timestamp TYPE TIMESTAMPTZ USING to_timestamp(timestamp),
+	ALTER COLUMN last_seen TYPE TIMESTAMPTZ USING to_timestamp(last_seen),
+	ALTER COLUMN node_id TYPE BYTEA USING decode(node_id, 'hex'),
+	ALTER COLUMN ip TYPE INET USING text_to_ip(ip),
+	ALTER COLUMN ip6 TYPE INET USING text_to_ip(ip6),
+	ALTER COLUMN pubkey TYPE BYTEA USING decode(pubkey, 'hex'),
+	ALTER COLUMN fork_digest TYPE BYTEA USING decode(substring(fork_digest FROM 3), 'hex'),
+	ALTER COLUMN next_fork_version TYPE BYTEA USING decode(substring(next_fork_version FROM 3), 'hex'),
+	ALTER COLUMN attnets TYPE BIT(64) USING ssz_hex_to_bits(attnets, 64),
+	ALTER COLUMN syncnets TYPE BIT(4) USING ssz_hex_to_bits(syncnets, 4);
+
+ALTER TABLE foreign_enrs
+	ALTER COLUMN ip DROP NOT NULL,
+	ALTER COLUMN timestamp TYPE TIMESTAMPTZ USING to_timestamp(timestamp),
+	ALTER COLUMN last_seen TYPE TIMESTAMPTZ USING to_timestamp(last_seen),
+	ALTER COLUMN node_id TYPE BYTEA USING decode(node_id, 'hex'),
+	ALTER COLUMN ip TYPE INET USING text_to_ip(ip),
+	ALTER COLUMN ip6 TYPE INET USING text_to_ip(ip6),
+	ALTER COLUMN pubkey TYPE BYTEA USING decode(pubkey, 'hex'),
+	ALTER COLUMN fork_digest TYPE BYTEA USING decode(substring(fork_digest FROM 3), 'hex'),
+	ALTER COLUMN next_fork_version TYPE BYTEA USING decode(substring(next_fork_version FROM 3), 'hex'),
+	ALTER COLUMN attnets TYPE BIT(64) USING ssz_hex_to_bits(attnets, 64),
+	ALTER COLUMN syncnets TYPE BIT(4) USING ssz_hex_to_bits(syncnets, 4);
+
+ALTER TABLE enr_history
+	ALTER COLUMN ip DROP NOT NULL,
+	ALTER COLUMN first_seen TYPE TIMESTAMPTZ USING to_timestamp(first_seen),
+	ALTER COLUMN last_seen TYPE TIMESTAMPTZ USING to_timestamp(last_seen),
+	ALTER COLUMN node_id TYPE BYTEA USING decode(node_id, 'hex'),
+	ALTER COLUMN ip TYPE INET USING text_to_ip(ip),
+	ALTER COLUMN ip6 TYPE INET USING text_to_ip(ip6),
+	ALTER COLUMN pubkey TYPE BYTEA USING decode(pubkey, 'hex'),
+	ALTER COLUMN fork_digest TYPE BYTEA USING decode(substring(fork_digest FROM 3), 'hex'),
+	ALTER COLUMN next_fork_version TYPE BYTEA USING decode(substring(next_fork_version FROM 3), 'hex'),
+	ALTER COLUMN attnets TYPE BIT(64) USING ssz_hex_to_bits(attnets, 64),
+	ALTER COLUMN syncnets TYPE BIT(4) USING ssz_hex_to_bits(syncnets, 4);
+
+ALTER TABLE routing_tables
+	ALTER COLUMN timestamp TYPE TIMESTAMPTZ USING to_timestamp(timestamp),
+	ALTER COLUMN node_id TYPE BYTEA USING decode(node_id, 'hex'),
+	ALTER COLUMN neighbors TYPE BYTEA[] USING hex_array_to_bytea(neighbors);
+
+ALTER TABLE node_liveness
+	ALTER COLUMN last_ping TYPE TIMESTAMPTZ USING to_timestamp(last_ping),
+	ALTER COLUMN last_seen_alive TYPE TIMESTAMPTZ USING to_timestamp(last_seen_alive),
+	ALTER COLUMN node_id TYPE BYTEA USING decode(node_id, 'hex');
+
+DROP FUNCTION ssz_hex_to_bits(TEXT, INT);
+DROP FUNCTION text_to_ip(TEXT);
+DROP FUNCTION hex_array_to_bytea(TEXT[]);
@@ -0,0 +1,15 @@
+-- keep a single observation of each version of the ENRs (the one of the latest crawl,
+-- breaking the ties between the rows of the same crawl by their physical location)
+DELETE FROM enr_history a
+	USING enr_history b
+	WHERE a.node_id = b.node_id AND a.seq = b.seq
+		AND (COALESCE(a.crawl_id, 0), a.ctid) < (COALESCE(b.crawl_id, 0), b.ctid);
+
+ALTER TABLE node_liveness DROP COLUMN crawl_id;
+ALTER TABLE routing_tables DROP COLUMN crawl_id;
+DROP INDEX IF EXISTS enr_history_version_key;
+ALTER TABLE enr_history
+	DROP COLUMN crawl_id,
+	ADD PRIMARY KEY(node_id, seq);
+
+DROP TABLE IF EXISTS crawls;
@@ -0,0 +1,28 @@
+-- each run of the crawler, along with its parameters and results
+CREATE TABLE IF NOT EXISTS crawls(
+	id BIGSERIAL,
+	start_time TIMESTAMPTZ NOT NULL,
+	end_time TIMESTAMPTZ,
+	peer_id BYTEA NOT NULL,
+	config JSONB NOT NULL,
+	discovered BIGINT NOT NULL DEFAULT 0,
+	new_nodes BIGINT NOT NULL DEFAULT 0,
+	updated_nodes BIGINT NOT NULL DEFAULT 0,
+	foreign_nodes BIGINT NOT NULL DEFAULT 0,
+	failed_nodes BIGINT NOT NULL DEFAULT 0,
+	status TEXT NOT NULL,
+
+	PRIMARY KEY(id)
+);
+
+-- the versions of the ENRs are tracked per crawl, so that the crawls can be compared
+-- (the observations previous to this migration don't reference any crawl)
+ALTER TABLE enr_history
+	ADD COLUMN crawl_id BIGINT REFERENCES crawls(id),
+	DROP CONSTRAINT enr_history_pkey;
+-- the NULLs would be distinct in a plain unique constraint, so the versions without crawl are keyed as crawl 0
+CREATE UNIQUE INDEX enr_history_version_key ON enr_history(node_id, seq, COALESCE(crawl_id, 0));
+
+-- as well as the routing tables and the latest liveness probe of the nodes
+ALTER TABLE routing_tables ADD COLUMN crawl_id BIGINT REFERENCES crawls(id);
+ALTER TABLE node_liveness ADD COLUMN crawl_id BIGINT REFERENCES crawls(id);
@@ -0,0 +1,2 @@
+ALTER TABLE enrs DROP COLUMN IF EXISTS source;
+ALTER TABLE foreign_enrs DROP COLUMN IF EXISTS source;
@@ -0,0 +1,4 @@
+-- where the stored version of each record comes from: the discv5 crawl, or an import
+-- of ENRs collected from other sources (i.e. client bootnode lists)
+ALTER TABLE enrs ADD COLUMN IF NOT EXISTS source TEXT NOT NULL DEFAULT 'discv5';
+ALTER TABLE foreign_enrs ADD COLUMN IF NOT EXISTS source TEXT NOT NULL DEFAULT 'discv5';
@@ -0,0 +1,106 @@
+package db
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+func migrationFS(names ...string) fstest.MapFS {
+	fsys := make(fstest.MapFS)
+	for _, name := range names {
+		fsys["migrations/"+name] = &fstest.MapFile{Data: []byte("-- " + name)}
+	}
+	return fsys
+}
+
+func TestReadMigrations(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    []string
+		versions []int
+		valid    bool
+	}{
+		{
+			name: "sorted by numeric version",
+			files: []string{
+				"10_ten.up.sql", "10_ten.down.sql", "2_two.up.sql", "2_two.down.sql", "1_one.down.sql", "1_one.up.sql",
+				"3_three.up.sql", "3_three.down.sql", "4_four.up.sql", "4_four.down.sql", "5_five.up.sql", "5_five.down.sql",
+				"6_six.up.sql", "6_six.down.sql", "7_seven.up.sql", "7_seven.down.sql", "8_eight.up.sql", "8_eight.down.sql",
+				"9_nine.up.sql", "9_nine.down.sql",
+			},
+			versions: []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10},
+			valid:    true,
+		},
+		{
+			name:     "single migration",
+			files:    []string{"1_one.up.sql", "1_one.down.sql"},
+			versions: []int{1},
+			valid:    true,
+		},
+		{
+			name:  "version gap",
+			files: []string{"0001_one.up.sql", "0001_one.down.sql", "0003_three.up.sql", "0003_three.down.sql"},
+		},
+		{
+			name:  "not starting at 1",
+			files: []string{"0002_two.up.sql", "0002_two.down.sql"},
+		},
+		{
+			name:  "missing down file",
+			files: []string{"0001_one.up.sql", "0001_one.down.sql", "0002_two.up.sql"},
+		},
+		{
+			name:  "missing up file",
+			files: []string{"0001_one.down.sql"},
+		},
+		{
+			name:  "invalid file name",
+			files: []string{"0001_one.up.sql", "0001_one.down.sql", "0002_two.sql"},
+		},
+		{
+			name:  "different names",
+			files: []string{"0001_one.up.sql", "0001_uno.down.sql"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			migrations, err := readMigrations(migrationFS(test.files...), "migrations")
+			if !test.valid {
+				if err == nil {
+					t.Fatalf("expected an error, got %d migrations", len(migrations))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(migrations) != len(test.versions) {
+				t.Fatalf("read %d migrations, expected %d", len(migrations), len(test.versions))
+			}
+			for i, migration := range migrations {
+				if migration.Version != test.versions[i] {
+					t.Errorf("migration %d has version %d, expected %d", i, migration.Version, test.versions[i])
+				}
+				if migration.up == "" || migration.down == "" {
+					t.Errorf("migration %d is missing its up or down content", migration.Version)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadMigrations(t *testing.T) {
+	migrations, err := loadMigrations()
+	if err != nil {
+		t.Fatalf("unable to load the embedded migrations: %v", err)
+	}
+	if len(migrations) == 0 {
+		t.Fatal("no embedded migrations")
+	}
+	for i, migration := range migrations {
+		if migration.Version != i+1 {
+			t.Errorf("migration %s has version %d, expected %d", migration.Name, migration.Version, i+1)
+		}
+	}
+}
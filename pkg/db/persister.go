@@ -0,0 +1,249 @@
+package db
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/jackc/pgx/v4"
+	"github.com/migalabs/eth-light-crawler/pkg/discv5"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// how often the persister stats get logged
+const persisterStatsInterval = 1 * time.Minute
+
+// max time given to the persisters to flush their last batch once the context of the tool died
+const persisterFlushTimeout = 5 * time.Second
+
+// dbQuery is a SQL statement along with its arguments,
+// so that it can be executed on its own or queued in a batch
+type dbQuery struct {
+	sql  string
+	args []interface{}
+}
+
+func newDBQuery(sql string, args ...interface{}) dbQuery {
+	return dbQuery{
+		sql:  sql,
+		args: args,
+	}
+}
+
+// execQueries executes the given queries one after the other, stopping at the first error
+func (c *DBClient) execQueries(ctx context.Context, queries ...dbQuery) error {
+	for _, query := range queries {
+		_, err := c.psqlPool.Exec(ctx, query.sql, query.args...)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// itemQueries composes the queries that persist the given item
+func itemQueries(obj *PersistableItem) ([]dbQuery, error) {
+	switch obj.Action {
+	case insertItem, updateItem:
+		// the ENRs are upserted, so insert and update are the same operation
+		switch item := obj.Item.(type) {
+		case *discv5.EnrNode:
+			return upsertEnrQueries(enrsTable, item, CrawlerSource, true)
+		case *ForeignEnr:
+			return upsertEnrQueries(foreignEnrsTable, item.EnrNode, CrawlerSource, true)
+		case *ImportedEnr:
+			return upsertEnrQueries(enrsTable, item.EnrNode, item.Source, false)
+		case *EnrHistory:
+			return []dbQuery{insertEnrHistoryQuery(item)}, nil
+		case *RoutingTable:
+			return []dbQuery{insertRoutingTableQuery(item)}, nil
+		case *PingResult:
+			return []dbQuery{upsertPingResultQuery(item)}, nil
+		default:
+			return nil, errors.Errorf("unrecognized type of object %T received to persist into DB", obj.Item)
+		}
+	case deleteItem:
+		return nil, errors.New("delete operation still not supported")
+	default:
+		return nil, errors.Errorf("unable to understand operation %d", obj.Action)
+	}
+}
+
+// itemNodeID returns the ID of the node whose rows the item writes (the zero ID for unknown items)
+func itemNodeID(item interface{}) enode.ID {
+	switch item := item.(type) {
+	case *discv5.EnrNode:
+		return item.ID
+	case *ForeignEnr:
+		return item.ID
+	case *ImportedEnr:
+		return item.ID
+	case *EnrHistory:
+		return item.ID
+	case *RoutingTable:
+		return item.NodeID
+	case *PingResult:
+		return item.NodeID
+	default:
+		return enode.ID{}
+	}
+}
+
+func (c *DBClient) launchPersister(persisterID int) {
+	logEntry := logrus.WithFields(logrus.Fields{"persisterID": persisterID})
+	persistC := c.persistCs[persisterID-1]
+
+	go func() {
+		defer c.persistWG.Done()
+		var sdFlag bool = false
+
+		// accumulate the items until the batch is full or the flush interval is reached
+		batch := make([]*PersistableItem, 0, c.batchSize)
+		flush := func(ctx context.Context) {
+			if len(batch) == 0 {
+				return
+			}
+			c.persistBatchFn(ctx, logEntry, batch)
+			batch = batch[:0]
+		}
+		ticker := time.NewTicker(c.flushInterval)
+		defer ticker.Stop()
+
+		logEntry.Info("inititalizing persister")
+		for {
+			// check if we need to close the persiter (and if the channel still has stuff to read)
+			if (len(persistC) == 0) && sdFlag {
+				flush(c.ctx)
+				logEntry.Info("signal to close the persister detected and there is nothing to read, closing persister")
+				return
+			}
+
+			select {
+			case obj := <-persistC: // persist any kind of item
+				persistQueueDepth.Set(float64(c.queued()))
+				batch = append(batch, obj)
+				if len(batch) >= c.batchSize {
+					flush(c.ctx)
+				}
+
+			case <-ticker.C:
+				flush(c.ctx)
+
+			case <-c.ctx.Done(): // check if the context of the tool died
+				logEntry.Info("context died, clossing persister")
+				// don't lose the accumulated batch, giving it a last chance to reach the db
+				flushCtx, cancel := context.WithTimeout(context.Background(), persisterFlushTimeout)
+				flush(flushCtx)
+				cancel()
+				return
+
+			case <-c.doneC: // closed once by Close, so that every persister gets notified
+				sdFlag = true
+			}
+		}
+	}()
+}
+
+// persistBatch sends the queries of all the items in a single batch. Since a failing statement aborts
+// the whole batch, the queries are executed one by one if the batch fails
+func (c *DBClient) persistBatch(ctx context.Context, logEntry *logrus.Entry, items []*PersistableItem) {
+	startT := time.Now()
+
+	queries := make([]dbQuery, 0, len(items))
+	for _, item := range items {
+		itemQs, err := itemQueries(item)
+		if err != nil {
+			writeErrors.WithLabelValues("compose").Inc()
+			logEntry.Error(err)
+			continue
+		}
+		queries = append(queries, itemQs...)
+	}
+	if len(queries) == 0 {
+		return
+	}
+
+	batch := &pgx.Batch{}
+	for _, query := range queries {
+		batch.Queue(query.sql, query.args...)
+	}
+	results := c.psqlPool.SendBatch(ctx, batch)
+	var err error
+	for range queries {
+		if _, err = results.Exec(); err != nil {
+			break
+		}
+	}
+	if closeErr := results.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		writeErrors.WithLabelValues("batch").Inc()
+		logEntry.Warnf("batch of %d queries failed, persisting them one by one - %s", len(queries), err.Error())
+		for _, query := range queries {
+			if err := c.execQueries(ctx, query); err != nil {
+				writeErrors.WithLabelValues("query").Inc()
+				logEntry.Error(errors.Wrap(err, "unable to persist item"))
+			}
+		}
+	}
+
+	latency := time.Since(startT)
+	c.stats.addFlush(len(items), latency)
+	writeLatency.Observe(latency.Seconds())
+	persistedItems.Add(float64(len(items)))
+	logEntry.WithFields(logrus.Fields{
+		"items":   len(items),
+		"queries": len(queries),
+		"latency": latency,
+		"queued":  c.queued(),
+	}).Debug("flushed batch to the db")
+}
+
+// logPersisterStats periodically reports the throughput of the persisters and the depth of their queue
+func (c *DBClient) logPersisterStats() {
+	ticker := time.NewTicker(persisterStatsInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			c.stats.log(c.queued(), c.queueSize())
+		case <-c.closeC:
+			return
+		case <-c.ctx.Done():
+			return
+		}
+	}
+}
+
+// persisterStats keeps the counters of the batches flushed to the db
+type persisterStats struct {
+	flushes      uint64
+	items        uint64
+	flushLatency int64 // nanoseconds
+}
+
+func (s *persisterStats) addFlush(items int, latency time.Duration) {
+	atomic.AddUint64(&s.flushes, 1)
+	atomic.AddUint64(&s.items, uint64(items))
+	atomic.AddInt64(&s.flushLatency, int64(latency))
+}
+
+func (s *persisterStats) log(queued int, queueSize int) {
+	flushes := atomic.LoadUint64(&s.flushes)
+	var avgLatency time.Duration
+	if flushes > 0 {
+		avgLatency = time.Duration(atomic.LoadInt64(&s.flushLatency) / int64(flushes))
+	}
+	logrus.WithFields(logrus.Fields{
+		"flushes":       flushes,
+		"items":         atomic.LoadUint64(&s.items),
+		"avg_latency":   avgLatency,
+		"queued":        queued,
+		"queue_percent": queued * 100 / queueSize,
+	}).Info("db persister stats")
+}
@@ -0,0 +1,120 @@
+package db
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/migalabs/eth-light-crawler/pkg/discv5"
+	"github.com/sirupsen/logrus"
+)
+
+// flushRecorder replaces the batch writes of the persisters, keeping which persister wrote each node
+type flushRecorder struct {
+	m sync.Mutex
+	// persister that wrote the rows of each node
+	writers map[enode.ID]interface{}
+	// last seq written by each producer for each node
+	lastSeqs map[enode.ID]map[uint64]uint64
+	items    int
+	errs     []string
+}
+
+func (r *flushRecorder) persistBatch(ctx context.Context, logEntry *logrus.Entry, items []*PersistableItem) {
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	persister := logEntry.Data["persisterID"]
+	for _, item := range items {
+		enr := item.Item.(*discv5.EnrNode)
+		if writer, ok := r.writers[enr.ID]; ok && writer != persister {
+			r.errs = append(r.errs, "node written by two persisters")
+		}
+		r.writers[enr.ID] = persister
+
+		// the items of a producer reach the db in the order they were queued
+		producer, seq := enr.Seq>>32, enr.Seq&0xffffffff
+		if r.lastSeqs[enr.ID] == nil {
+			r.lastSeqs[enr.ID] = make(map[uint64]uint64)
+		}
+		if last, ok := r.lastSeqs[enr.ID][producer]; ok && seq <= last {
+			r.errs = append(r.errs, "items of a producer written out of order")
+		}
+		r.lastSeqs[enr.ID][producer] = seq
+		r.items++
+	}
+}
+
+func TestPersistersOverlappingNodes(t *testing.T) {
+	tests := []struct {
+		name       string
+		persisters int
+		batchSize  int
+		producers  int
+		nodes      int
+		updates    int
+	}{
+		{name: "single persister", persisters: 1, batchSize: 10, producers: 4, nodes: 16, updates: 50},
+		{name: "more nodes than persisters", persisters: 4, batchSize: 8, producers: 8, nodes: 64, updates: 50},
+		{name: "more persisters than nodes", persisters: 16, batchSize: 4, producers: 8, nodes: 3, updates: 100},
+		{name: "unbatched", persisters: 4, batchSize: 1, producers: 4, nodes: 32, updates: 20},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			recorder := &flushRecorder{
+				writers:  make(map[enode.ID]interface{}),
+				lastSeqs: make(map[enode.ID]map[uint64]uint64),
+			}
+			client := &DBClient{
+				ctx:           context.Background(),
+				persistCs:     make([]chan *PersistableItem, test.persisters),
+				persistWG:     new(sync.WaitGroup),
+				doneC:         make(chan struct{}),
+				maxPersisters: test.persisters,
+				batchSize:     test.batchSize,
+				flushInterval: time.Millisecond,
+				stats:         new(persisterStats),
+				closeC:        make(chan struct{}),
+			}
+			for i := range client.persistCs {
+				client.persistCs[i] = make(chan *PersistableItem, bufferSize)
+			}
+			client.persistBatchFn = recorder.persistBatch
+			client.spawnPersisters()
+
+			// every producer updates every node, so that all the nodes get written concurrently
+			var producersWG sync.WaitGroup
+			for producer := 0; producer < test.producers; producer++ {
+				producersWG.Add(1)
+				go func(producer uint64) {
+					defer producersWG.Done()
+					for seq := uint64(1); seq <= uint64(test.updates); seq++ {
+						for node := 0; node < test.nodes; node++ {
+							var id enode.ID
+							// spread the IDs over the whole space, as the node IDs of the network are
+							id[0], id[7] = byte(node*37), byte(node)
+							enr := discv5.NewEnrNode(id)
+							enr.Seq = producer<<32 | seq
+							client.InsertIntoDB(enr)
+						}
+					}
+				}(uint64(producer))
+			}
+			producersWG.Wait()
+
+			close(client.doneC)
+			client.persistWG.Wait()
+			close(client.closeC)
+
+			for _, err := range recorder.errs {
+				t.Error(err)
+			}
+			if expected := test.producers * test.nodes * test.updates; recorder.items != expected {
+				t.Fatalf("persisted %d items, expected %d", recorder.items, expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package db
+
+import (
+	"github.com/migalabs/eth-light-crawler/pkg/discv5"
+)
+
+// RoutingTable wraps the neighbors returned by a node during the targeted crawl,
+// along with the crawl that requested them
+type RoutingTable struct {
+	*discv5.RoutingTable
+	// crawl that walked the k-buckets of the node (0 = none)
+	CrawlID int64
+}
+
+// InsertRoutingTable queues the neighbors that a node returned when walking its k-buckets
+func (d *DBClient) InsertRoutingTable(rt *RoutingTable) error {
+	d.InsertIntoDB(rt)
+	return nil
+}
+
+func insertRoutingTableQuery(rt *RoutingTable) dbQuery {
+	neighbors := make([][]byte, 0, len(rt.Neighbors))
+	for _, neighbor := range rt.Neighbors {
+		id := neighbor.ID()
+		neighbors = append(neighbors, id[:])
+	}
+	var rtErr interface{}
+	if rt.Err != nil {
+		rtErr = rt.Err.Error()
+	}
+
+	return newDBQuery(
+		`
+			INSERT INTO routing_tables(
+				timestamp,
+				node_id,
+				neighbors,
+				neighbors_number,
+				error,
+				crawl_id)
+			VALUES($1,$2,$3,$4,$5,$6)
+		`,
+		rt.Timestamp,
+		rt.NodeID[:],
+		neighbors,
+		len(neighbors),
+		rtErr,
+		nullableCrawlID(rt.CrawlID),
+	)
+}
@@ -2,12 +2,12 @@ package db
 
 import (
 	"context"
+	"encoding/binary"
 	"sync"
+	"time"
 
 	"github.com/sirupsen/logrus"
 
-	"github.com/migalabs/eth-light-crawler/pkg/discv5"
-
 	"github.com/jackc/pgx/v4/pgxpool"
 	"github.com/pkg/errors"
 )
@@ -20,10 +20,7 @@ const (
 	deleteItem
 )
 
-const (
-	bufferSize    = 2048
-	maxPersisters = 1
-)
+const bufferSize = 2048
 
 type DBClient struct {
 	// Control Variables
@@ -34,17 +31,30 @@ type DBClient struct {
 	loginStr string
 	psqlPool *pgxpool.Pool
 
-	persistC   chan *PersistableItem
+	// one queue per persister, the items of a node always go to the same one
+	persistCs  []chan *PersistableItem
 	persisters int
 	persistWG  *sync.WaitGroup
 	doneC      chan struct{}
+
+	// batching of the persisted items
+	maxPersisters int
+	batchSize     int
+	flushInterval time.Duration
+	stats         *persisterStats
+	closeC        chan struct{}
+	// writes a batch of items (persistBatch, unless replaced by the tests)
+	persistBatchFn func(ctx context.Context, logEntry *logrus.Entry, items []*PersistableItem)
 }
 
 func NewDBClient(
 	ctx context.Context,
 	loginStr string,
 	initialized bool,
-	reset bool) (*DBClient, error) {
+	reset bool,
+	persisters int,
+	batchSize int,
+	flushInterval time.Duration) (*DBClient, error) {
 
 	logEntry := logrus.WithField("module", "db-client")
 	logEntry.WithFields(logrus.Fields{"endpoint": loginStr}).Debug("attempt connection to DB")
@@ -53,6 +63,9 @@ func NewDBClient(
 	if len(loginStr) == 0 {
 		return nil, errors.New("empty db-endpoint provided")
 	}
+	if persisters < 1 || batchSize < 1 || flushInterval <= 0 {
+		return nil, errors.New("db persisters, batch size and flush interval must be positive")
+	}
 
 	// try connecting to the DB from the given logingStr
 	pPool, err := pgxpool.Connect(ctx, loginStr)
@@ -63,11 +76,15 @@ func NewDBClient(
 	// check if the connection is successful
 	err = pPool.Ping(ctx)
 	if err != nil {
+		pPool.Close()
 		return nil, errors.Wrap(err, "unable to ping db")
 	}
 
 	// generate all the necessary/control channels
-	persistC := make(chan *PersistableItem, bufferSize)
+	persistCs := make([]chan *PersistableItem, persisters)
+	for i := range persistCs {
+		persistCs[i] = make(chan *PersistableItem, bufferSize)
+	}
 	var persistWG sync.WaitGroup
 
 	// compose the DBClient
@@ -75,130 +92,111 @@ func NewDBClient(
 		ctx:       ctx,
 		loginStr:  loginStr,
 		psqlPool:  pPool,
-		persistC:  persistC,
+		persistCs: persistCs,
 		persistWG: &persistWG,
 		doneC:     make(chan struct{}),
+
+		maxPersisters: persisters,
+		batchSize:     batchSize,
+		flushInterval: flushInterval,
+		stats:         new(persisterStats),
+		closeC:        make(chan struct{}),
 	}
+	dbClient.persistBatchFn = dbClient.persistBatch
 
 	// initialize all the tables
 	if initialized {
 		err = dbClient.initTables(reset)
 		if err != nil {
+			pPool.Close()
 			return nil, errors.Wrap(err, "unable to initialize the SQL tables at "+loginStr)
 		}
 	}
 
 	// run the db persisters
-	go dbClient.spawnPersisters()
+	dbClient.spawnPersisters()
 
 	return dbClient, nil
 }
 
+// initTables migrates the DB schema to the latest version (reverting all the migrations first
+// if a reset was requested), refusing to run against a schema newer than the supported one
 func (c *DBClient) initTables(resetTables bool) error {
-	// initialize all the necessary tables to perform the crawl
-
-	var err error
+	migrator, err := newMigrator(c.ctx, c.psqlPool)
+	if err != nil {
+		return err
+	}
 
-	// drop Enr Table if requested
 	if resetTables {
-		err = c.dropEnrTable()
+		// migrate up first, so that the tables created before the migrations get dropped too
+		_, err = migrator.Up()
 		if err != nil {
 			return err
 		}
+		reverted, err := migrator.Down(0)
+		if err != nil {
+			return errors.Wrap(err, "unable to reset the db")
+		}
+		logrus.Infof("reverted %d migrations to reset the db", reverted)
 	}
 
-	// init Enr table
-	err = c.initEnrDatabase()
+	applied, err := migrator.Up()
 	if err != nil {
 		return err
 	}
+	logrus.WithFields(logrus.Fields{
+		"applied": applied,
+		"version": migrator.LatestVersion(),
+	}).Info("db schema up to date")
+	return nil
+}
 
+// checkSchemaVersion returns an error unless the DB schema is at the latest supported version,
+// for the clients that don't migrate it
+func (c *DBClient) checkSchemaVersion() error {
+	migrations, err := loadMigrations()
+	if err != nil {
+		return err
+	}
+	latest := migrations[len(migrations)-1].Version
+	version, err := currentVersion(c.ctx, c.psqlPool)
+	if err != nil {
+		return errors.Wrap(err, "unable to check the db schema, please run the migrate command")
+	}
+	switch {
+	case version < latest:
+		return errors.Errorf("db schema version %d is older than the expected one (%d), please run the migrate command", version, latest)
+	case version > latest:
+		return errors.Errorf("db schema version %d is newer than the latest supported one (%d), please upgrade the crawler", version, latest)
+	}
 	return nil
 }
 
 func (c *DBClient) spawnPersisters() {
 	// spaw as many persisters as defined in `maxPersisters`
-	for persister := 1; persister <= maxPersisters; persister++ {
-		c.launchPersister(persister)
+	for persister := 1; persister <= c.maxPersisters; persister++ {
 		c.persistWG.Add(1)
+		c.launchPersister(persister)
 		c.persisters++
 	}
 	logrus.Debugf("spawned total of %d db persister", c.persisters)
-}
-
-func (c *DBClient) launchPersister(persisterID int) error {
-	logEntry := logrus.WithFields(logrus.Fields{"persisterID": persisterID})
-
-	go func() {
-		defer c.persistWG.Done()
-		var sdFlag bool = false
-
-		logEntry.Info("inititalizing persister")
-		// check with higher priority if the main-ctx died
-		for {
-			// check if we need to close the persiter (and if the channel still has stuff to read)
-			if (len(c.persistC) == 0) && sdFlag {
-				logEntry.Info("signal to close the persister detected and there is nothing to read, closing persister")
-				return
-			}
-
-			select {
-			case obj := <-c.persistC: // persist any kind of item
-				switch obj.Action {
-				case insertItem:
-					switch obj.Item.(type) {
-					case (*discv5.EnrNode):
-						enr := obj.Item.(*discv5.EnrNode)
-						logrus.Debugf("updating enr for node %s", enr.ID)
-						err := c.InsertEnr(enr)
-						if err != nil {
-							logEntry.Error(err)
-						}
-					default:
-						logEntry.Error("unrecognized type of object received to insert into DB", obj)
-					}
-				case updateItem:
-					switch obj.Item.(type) {
-					case (*discv5.EnrNode):
-						enr := obj.Item.(*discv5.EnrNode)
-						logrus.Debugf("udpating enr for node %s", enr.ID)
-						err := c.UpdateEnr(enr)
-						if err != nil {
-							logEntry.Error(err)
-						}
-					default:
-						logEntry.Error("unrecognized type of object received to update into DB", obj)
-					}
-				case deleteItem:
-					logEntry.Info("Delete operation still not supported")
-				default:
-					logEntry.Info("unable to understand operation", obj.Action)
-				}
-			case <-c.ctx.Done(): // check if the context of the tool died
-				logEntry.Info("context died, clossing persister")
-				return
-
-			case <-c.doneC:
-				sdFlag = true
-			}
-		}
-	}()
-
-	return nil
 
+	go c.logPersisterStats()
 }
 
 func (c *DBClient) Close() {
-	// notify the persisters to finish
-	for i := 0; i < c.persisters; i++ {
-		c.doneC <- struct{}{}
-	}
+	// notify all the persisters to finish (closing the channel, so that a persister
+	// that is still draining the queue can't take the signal of another one)
+	close(c.doneC)
 
 	c.persistWG.Wait()
+	close(c.closeC)
+	c.stats.log(c.queued(), c.queueSize())
 
 	// close all the exisiting channels
-	close(c.doneC)
-	close(c.persistC)
+	for _, persistC := range c.persistCs {
+		close(persistC)
+	}
 
 	// close safelly the connection with PSQL
 	c.psqlPool.Close()
@@ -217,11 +215,33 @@ func newPersistable(item interface{}, action dbAction) *PersistableItem {
 }
 
 func (c *DBClient) InsertIntoDB(persItem interface{}) {
-	item := newPersistable(persItem, insertItem)
-	c.persistC <- item
+	c.enqueue(newPersistable(persItem, insertItem))
 }
 
 func (c *DBClient) UpdateInDB(persItem interface{}) {
-	item := newPersistable(persItem, updateItem)
-	c.persistC <- item
+	c.enqueue(newPersistable(persItem, updateItem))
+}
+
+// enqueue routes the item to the persister of its node, so that two persisters never write
+// the rows of the same node at once (their batches would lock them in opposite order and deadlock)
+func (c *DBClient) enqueue(item *PersistableItem) {
+	nodeID := itemNodeID(item.Item)
+	// the node IDs are hashes, so their first bytes are already evenly distributed
+	persister := binary.BigEndian.Uint64(nodeID[:8]) % uint64(len(c.persistCs))
+	c.persistCs[persister] <- item
+	persistQueueDepth.Set(float64(c.queued()))
+}
+
+// queued returns the number of items waiting in the queues of all the persisters
+func (c *DBClient) queued() int {
+	queued := 0
+	for _, persistC := range c.persistCs {
+		queued += len(persistC)
+	}
+	return queued
+}
+
+// queueSize returns the capacity of the queues of all the persisters
+func (c *DBClient) queueSize() int {
+	return bufferSize * len(c.persistCs)
 }
@@ -0,0 +1,710 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	gcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/migalabs/eth-light-crawler/pkg/discv5"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	// pure go SQLite driver (no cgo), registered as "sqlite"
+	_ "modernc.org/sqlite"
+)
+
+// the SQLite store mirrors the postgres tables, with the ids, keys and bitvectors hex encoded
+// and the timestamps as unix seconds
+const sqliteSchema = `
+	CREATE TABLE IF NOT EXISTS %[1]s(
+		node_id TEXT NOT NULL PRIMARY KEY,
+		timestamp INTEGER NOT NULL,
+		last_seen INTEGER,
+		seq INTEGER NOT NULL,
+		ip TEXT,
+		tcp INTEGER,
+		udp INTEGER,
+		ip6 TEXT,
+		tcp6 INTEGER,
+		udp6 INTEGER,
+		pubkey TEXT NOT NULL,
+		fork_digest TEXT,
+		next_fork_version TEXT,
+		attnets TEXT,
+		attnets_number INTEGER,
+		syncnets TEXT,
+		syncnets_number INTEGER,
+		enr TEXT,
+		enr_fields TEXT,
+		source TEXT NOT NULL DEFAULT 'discv5'
+	);
+
+	CREATE TABLE IF NOT EXISTS %[2]s(
+		node_id TEXT NOT NULL PRIMARY KEY,
+		timestamp INTEGER NOT NULL,
+		last_seen INTEGER,
+		seq INTEGER NOT NULL,
+		ip TEXT,
+		tcp INTEGER,
+		udp INTEGER,
+		ip6 TEXT,
+		tcp6 INTEGER,
+		udp6 INTEGER,
+		pubkey TEXT NOT NULL,
+		fork_digest TEXT,
+		next_fork_version TEXT,
+		attnets TEXT,
+		attnets_number INTEGER,
+		syncnets TEXT,
+		syncnets_number INTEGER,
+		enr TEXT,
+		enr_fields TEXT,
+		source TEXT NOT NULL DEFAULT 'discv5'
+	);
+
+	CREATE TABLE IF NOT EXISTS enr_history(
+		node_id TEXT NOT NULL,
+		seq INTEGER NOT NULL,
+		first_seen INTEGER NOT NULL,
+		last_seen INTEGER NOT NULL,
+		ip TEXT,
+		tcp INTEGER,
+		udp INTEGER,
+		ip6 TEXT,
+		tcp6 INTEGER,
+		udp6 INTEGER,
+		pubkey TEXT NOT NULL,
+		fork_digest TEXT,
+		next_fork_version TEXT,
+		attnets TEXT,
+		attnets_number INTEGER,
+		syncnets TEXT,
+		syncnets_number INTEGER,
+		enr TEXT NOT NULL,
+		crawl_id INTEGER REFERENCES crawls(id)
+	);
+
+	CREATE TABLE IF NOT EXISTS routing_tables(
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		timestamp INTEGER NOT NULL,
+		node_id TEXT NOT NULL,
+		neighbors TEXT NOT NULL,
+		neighbors_number INTEGER NOT NULL,
+		error TEXT,
+		crawl_id INTEGER REFERENCES crawls(id)
+	);
+
+	CREATE TABLE IF NOT EXISTS crawls(
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		start_time INTEGER NOT NULL,
+		end_time INTEGER,
+		peer_id TEXT NOT NULL,
+		config TEXT NOT NULL,
+		discovered INTEGER NOT NULL DEFAULT 0,
+		new_nodes INTEGER NOT NULL DEFAULT 0,
+		updated_nodes INTEGER NOT NULL DEFAULT 0,
+		foreign_nodes INTEGER NOT NULL DEFAULT 0,
+		failed_nodes INTEGER NOT NULL DEFAULT 0,
+		status TEXT NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS node_liveness(
+		node_id TEXT NOT NULL PRIMARY KEY,
+		last_ping INTEGER NOT NULL,
+		last_seen_alive INTEGER,
+		rtt_ms REAL,
+		ping_successes INTEGER NOT NULL,
+		ping_failures INTEGER NOT NULL,
+		consecutive_failures INTEGER NOT NULL,
+		online BOOLEAN NOT NULL,
+		crawl_id INTEGER REFERENCES crawls(id)
+	);
+`
+
+// SQLiteStore keeps the crawled data in an embedded SQLite file, so that the crawler
+// can run without a postgres server
+type SQLiteStore struct {
+	ctx context.Context
+	db  *sql.DB
+}
+
+func NewSQLiteStore(ctx context.Context, path string, reset bool) (*SQLiteStore, error) {
+	if len(path) == 0 {
+		return nil, errors.New("empty sqlite file path provided")
+	}
+	log.WithField("path", path).Debug("opening sqlite db")
+
+	db, err := sql.Open("sqlite", path+"?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)")
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to open the sqlite db")
+	}
+	// SQLite allows a single writer
+	db.SetMaxOpenConns(1)
+
+	s := &SQLiteStore{
+		ctx: ctx,
+		db:  db,
+	}
+	if reset {
+		_, err = db.ExecContext(ctx, `
+			DROP TABLE IF EXISTS enrs;
+			DROP TABLE IF EXISTS foreign_enrs;
+			DROP TABLE IF EXISTS enr_history;
+			DROP TABLE IF EXISTS routing_tables;
+			DROP TABLE IF EXISTS node_liveness;
+			DROP TABLE IF EXISTS crawls;
+		`)
+		if err != nil {
+			db.Close()
+			return nil, errors.Wrap(err, "unable to reset the sqlite db")
+		}
+	}
+	_, err = db.ExecContext(ctx, fmt.Sprintf(sqliteSchema, enrsTable, foreignEnrsTable))
+	if err != nil {
+		db.Close()
+		return nil, errors.Wrap(err, "unable to create the sqlite tables")
+	}
+	// the files created before the ENRs were tagged with their source lack the column
+	for _, table := range []string{enrsTable, foreignEnrsTable} {
+		if err := s.addColumn(table, "source", "TEXT NOT NULL DEFAULT 'discv5'"); err != nil {
+			db.Close()
+			return nil, err
+		}
+	}
+	// as well as the crawl of the routing tables and the liveness probes
+	for _, table := range []string{"routing_tables", "node_liveness"} {
+		if err := s.addColumn(table, "crawl_id", "INTEGER REFERENCES crawls(id)"); err != nil {
+			db.Close()
+			return nil, err
+		}
+	}
+	if err := s.addHistoryVersionKey(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// addHistoryVersionKey creates the unique index of the ENR versions per crawl, unless it already exists.
+// The NULLs would be distinct in a plain unique constraint, so the versions without crawl are keyed as
+// crawl 0, keeping a single observation of them in the files created before the index
+func (s *SQLiteStore) addHistoryVersionKey() error {
+	var count int
+	err := s.db.QueryRowContext(s.ctx, `SELECT COUNT(*) FROM sqlite_master WHERE type = 'index' AND name = 'enr_history_version_key'`).Scan(&count)
+	if err != nil {
+		return errors.Wrap(err, "unable to read the indexes of the enr_history table")
+	}
+	if count > 0 {
+		return nil
+	}
+	_, err = s.db.ExecContext(s.ctx, `
+		DELETE FROM enr_history WHERE rowid NOT IN (
+			SELECT MAX(rowid) FROM enr_history GROUP BY node_id, seq, COALESCE(crawl_id, 0)
+		);
+		CREATE UNIQUE INDEX enr_history_version_key ON enr_history(node_id, seq, COALESCE(crawl_id, 0));
+	`)
+	if err != nil {
+		return errors.Wrap(err, "unable to create the version key of the enr_history table")
+	}
+	return nil
+}
+
+// addColumn adds the column to the table, unless it already has it
+func (s *SQLiteStore) addColumn(table string, column string, definition string) error {
+	var count int
+	err := s.db.QueryRowContext(s.ctx, `SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = ?`, table, column).Scan(&count)
+	if err != nil {
+		return errors.Wrapf(err, "unable to read the columns of the %s table", table)
+	}
+	if count > 0 {
+		return nil
+	}
+	_, err = s.db.ExecContext(s.ctx, fmt.Sprintf(`ALTER TABLE %s ADD COLUMN %s %s`, table, column, definition))
+	if err != nil {
+		return errors.Wrapf(err, "unable to add the %s column to the %s table", column, table)
+	}
+	return nil
+}
+
+// exec runs a write on the db, keeping track of its latency and errors
+func (s *SQLiteStore) exec(query string, args ...interface{}) (sql.Result, error) {
+	startT := time.Now()
+	res, err := s.db.ExecContext(s.ctx, query, args...)
+	writeLatency.Observe(time.Since(startT).Seconds())
+	if err != nil {
+		writeErrors.WithLabelValues("query").Inc()
+		return nil, err
+	}
+	persistedItems.Inc()
+	return res, nil
+}
+
+// nullableHex returns the hex encoded bytes, or nil (NULL in the db) if there are none
+func nullableHex(b []byte) interface{} {
+	if len(b) == 0 {
+		return nil
+	}
+	return hex.EncodeToString(b)
+}
+
+func (s *SQLiteStore) UpsertEnr(enr *discv5.EnrNode) error {
+	return s.upsertEnr(enrsTable, enr, CrawlerSource, true)
+}
+
+func (s *SQLiteStore) UpsertForeignEnr(enr *ForeignEnr) error {
+	return s.upsertEnr(foreignEnrsTable, enr.EnrNode, CrawlerSource, true)
+}
+
+func (s *SQLiteStore) UpsertImportedEnr(enr *ImportedEnr) error {
+	return s.upsertEnr(enrsTable, enr.EnrNode, enr.Source, false)
+}
+
+// upsertEnr upserts the ENR tagged with its source, only moving forward the last_seen of the node if the crawler saw it
+func (s *SQLiteStore) upsertEnr(table string, enr *discv5.EnrNode, source string, seen bool) error {
+	fields, err := json.Marshal(enr.Fields)
+	if err != nil {
+		return errors.Wrap(err, "unable to marshal enr fields")
+	}
+
+	_, err = s.exec(
+		fmt.Sprintf(`
+			INSERT INTO %[1]s(
+				timestamp,
+				node_id,
+				seq,
+				ip,
+				tcp,
+				udp,
+				pubkey,
+				fork_digest,
+				next_fork_version,
+				attnets,
+				attnets_number,
+				syncnets,
+				syncnets_number,
+				enr,
+				enr_fields,
+				ip6,
+				tcp6,
+				udp6,
+				last_seen,
+				source)
+			VALUES(?1,?2,?3,?4,?5,?6,?7,?8,?9,?10,?11,?12,?13,?14,?15,?16,?17,?18,?20,?19)
+			ON CONFLICT (node_id) DO UPDATE SET
+				timestamp=excluded.timestamp,
+				seq=excluded.seq,
+				ip=excluded.ip,
+				tcp=excluded.tcp,
+				udp=excluded.udp,
+				pubkey=excluded.pubkey,
+				fork_digest=excluded.fork_digest,
+				next_fork_version=excluded.next_fork_version,
+				attnets=excluded.attnets,
+				attnets_number=excluded.attnets_number,
+				syncnets=excluded.syncnets,
+				syncnets_number=excluded.syncnets_number,
+				enr=excluded.enr,
+				enr_fields=excluded.enr_fields,
+				ip6=excluded.ip6,
+				tcp6=excluded.tcp6,
+				udp6=excluded.udp6,
+				source=excluded.source
+			WHERE excluded.seq > %[1]s.seq;
+
+			UPDATE %[1]s SET
+				last_seen=MAX(COALESCE(last_seen, 0), ?20)
+			WHERE node_id=?2 AND ?20 IS NOT NULL;
+		`, table),
+		enr.Timestamp.Unix(),
+		enr.ID.String(),
+		enr.Seq,
+		nullableIP(enr.IP),
+		enr.TCP,
+		enr.UDP,
+		hex.EncodeToString(gcrypto.FromECDSAPub(enr.Pubkey)),
+		enr.Eth2Data.ForkDigest.String(),
+		enr.Eth2Data.NextForkVersion.String(),
+		nullableHex(enr.Attnets.Raw[:]),
+		enr.Attnets.NetNumber,
+		nullableHex(enr.Syncnets.Raw[:]),
+		enr.Syncnets.NetNumber,
+		enr.Enr,
+		string(fields),
+		nullableIP(enr.IP6),
+		enr.TCP6,
+		enr.UDP6,
+		source,
+		nullableUnix(lastSeenTime(enr, seen)),
+	)
+	if err != nil {
+		return errors.Wrap(err, "unable to upsert enr")
+	}
+	return nil
+}
+
+func (s *SQLiteStore) InsertEnrHistory(enr *EnrHistory) error {
+	_, err := s.exec(
+		`
+			INSERT INTO enr_history(
+				node_id,
+				seq,
+				first_seen,
+				last_seen,
+				ip,
+				tcp,
+				udp,
+				pubkey,
+				fork_digest,
+				next_fork_version,
+				attnets,
+				attnets_number,
+				syncnets,
+				syncnets_number,
+				enr,
+				ip6,
+				tcp6,
+				udp6,
+				crawl_id)
+			VALUES(?1,?2,?3,?3,?4,?5,?6,?7,?8,?9,?10,?11,?12,?13,?14,?15,?16,?17,?18)
+			ON CONFLICT (node_id, seq, (COALESCE(crawl_id, 0))) DO UPDATE SET
+				last_seen = excluded.last_seen
+		`,
+		enr.ID.String(),
+		enr.Seq,
+		enr.Timestamp.Unix(),
+		nullableIP(enr.IP),
+		enr.TCP,
+		enr.UDP,
+		hex.EncodeToString(gcrypto.FromECDSAPub(enr.Pubkey)),
+		enr.Eth2Data.ForkDigest.String(),
+		enr.Eth2Data.NextForkVersion.String(),
+		nullableHex(enr.Attnets.Raw[:]),
+		enr.Attnets.NetNumber,
+		nullableHex(enr.Syncnets.Raw[:]),
+		enr.Syncnets.NetNumber,
+		enr.Enr,
+		nullableIP(enr.IP6),
+		enr.TCP6,
+		enr.UDP6,
+		nullableCrawlID(enr.CrawlID),
+	)
+	if err != nil {
+		return errors.Wrap(err, "unable to insert enr history")
+	}
+	return nil
+}
+
+func (s *SQLiteStore) InsertRoutingTable(rt *RoutingTable) error {
+	neighbors := make([]string, 0, len(rt.Neighbors))
+	for _, neighbor := range rt.Neighbors {
+		neighbors = append(neighbors, neighbor.ID().String())
+	}
+	var rtErr interface{}
+	if rt.Err != nil {
+		rtErr = rt.Err.Error()
+	}
+
+	_, err := s.exec(
+		`
+			INSERT INTO routing_tables(
+				timestamp,
+				node_id,
+				neighbors,
+				neighbors_number,
+				error,
+				crawl_id)
+			VALUES(?,?,?,?,?,?)
+		`,
+		rt.Timestamp.Unix(),
+		rt.NodeID.String(),
+		strings.Join(neighbors, ","),
+		len(neighbors),
+		rtErr,
+		nullableCrawlID(rt.CrawlID),
+	)
+	if err != nil {
+		return errors.Wrap(err, "unable to insert routing table")
+	}
+	return nil
+}
+
+func (s *SQLiteStore) UpsertPingResult(result *PingResult) error {
+	var successes, failures int
+	var lastSeenAlive, rtt interface{}
+	if result.Err != nil {
+		failures = 1
+	} else {
+		successes = 1
+		lastSeenAlive = result.Timestamp.Unix()
+		rtt = float64(result.RTT.Microseconds()) / 1000
+	}
+
+	_, err := s.exec(
+		`
+			INSERT INTO node_liveness(
+				node_id,
+				last_ping,
+				last_seen_alive,
+				rtt_ms,
+				ping_successes,
+				ping_failures,
+				consecutive_failures,
+				online,
+				crawl_id)
+			VALUES(?,?,?,?,?,?,?,?,?)
+			ON CONFLICT (node_id) DO UPDATE SET
+				last_ping = excluded.last_ping,
+				last_seen_alive = COALESCE(excluded.last_seen_alive, node_liveness.last_seen_alive),
+				rtt_ms = COALESCE(excluded.rtt_ms, node_liveness.rtt_ms),
+				ping_successes = node_liveness.ping_successes + excluded.ping_successes,
+				ping_failures = node_liveness.ping_failures + excluded.ping_failures,
+				consecutive_failures = excluded.consecutive_failures,
+				online = excluded.online,
+				crawl_id = excluded.crawl_id
+		`,
+		result.NodeID.String(),
+		result.Timestamp.Unix(),
+		lastSeenAlive,
+		rtt,
+		successes,
+		failures,
+		result.ConsecutiveFailures,
+		!result.Offline,
+		nullableCrawlID(result.CrawlID),
+	)
+	if err != nil {
+		return errors.Wrap(err, "unable to upsert ping result")
+	}
+	return nil
+}
+
+func (s *SQLiteStore) InsertCrawl(crawl *Crawl) error {
+	config, err := json.Marshal(crawl.Config)
+	if err != nil {
+		return errors.Wrap(err, "unable to marshal the crawl config")
+	}
+
+	res, err := s.exec(
+		`
+			INSERT INTO crawls(
+				start_time,
+				end_time,
+				peer_id,
+				config,
+				status)
+			VALUES(?,?,?,?,?)
+		`,
+		crawl.StartTime.Unix(),
+		nullableUnix(crawl.EndTime),
+		crawl.PeerID.String(),
+		string(config),
+		crawl.Status,
+	)
+	if err != nil {
+		return errors.Wrap(err, "unable to insert crawl")
+	}
+	crawl.ID, err = res.LastInsertId()
+	if err != nil {
+		return errors.Wrap(err, "unable to read the crawl id")
+	}
+	return nil
+}
+
+func (s *SQLiteStore) UpdateCrawl(crawl *Crawl) error {
+	_, err := s.exec(
+		`
+			UPDATE crawls SET
+				end_time=?2,
+				discovered=?3,
+				new_nodes=?4,
+				updated_nodes=?5,
+				foreign_nodes=?6,
+				failed_nodes=?7,
+				status=?8
+			WHERE id=?1
+		`,
+		crawl.ID,
+		nullableUnix(crawl.EndTime),
+		crawl.Discovered,
+		crawl.New,
+		crawl.Updated,
+		crawl.Foreign,
+		crawl.Failed,
+		crawl.Status,
+	)
+	if err != nil {
+		return errors.Wrap(err, "unable to update crawl")
+	}
+	return nil
+}
+
+// nullableUnix returns the unix seconds of the time, or nil (NULL in the db) for the zero time
+func nullableUnix(t time.Time) interface{} {
+	if t.IsZero() {
+		return nil
+	}
+	return t.Unix()
+}
+
+func (s *SQLiteStore) GetEnrSeqs(limit int) (map[enode.ID]uint64, error) {
+	rows, err := s.db.QueryContext(s.ctx, `
+		SELECT node_id, seq
+		FROM enrs
+		ORDER BY timestamp DESC
+		LIMIT ?
+	`, sqliteLimit(limit))
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to read the enr seq numbers")
+	}
+	defer rows.Close()
+
+	seqs := make(map[enode.ID]uint64)
+	for rows.Next() {
+		var nodeID string
+		var seq int64
+		if err := rows.Scan(&nodeID, &seq); err != nil {
+			return nil, errors.Wrap(err, "unable to read the enr seq numbers")
+		}
+		id, err := enode.ParseID(nodeID)
+		if err != nil {
+			log.Warnf("skipping invalid node_id %s in the enrs table - %s", nodeID, err.Error())
+			continue
+		}
+		seqs[id] = uint64(seq)
+	}
+	return seqs, rows.Err()
+}
+
+func (s *SQLiteStore) GetEnr(id enode.ID) (*discv5.EnrNode, error) {
+	enrs, err := s.queryEnrs(`
+		SELECT enr, timestamp
+		FROM enrs
+		WHERE node_id=? AND enr IS NOT NULL
+	`, id.String())
+	if err != nil {
+		return nil, err
+	}
+	if len(enrs) == 0 {
+		return nil, ErrNotFound
+	}
+	return enrs[0], nil
+}
+
+func (s *SQLiteStore) GetEnrs(filter *EnrFilter) ([]*discv5.EnrNode, error) {
+	conditions := []string{"enr IS NOT NULL"}
+	args := make([]interface{}, 0)
+	if len(filter.ForkDigests) > 0 {
+		placeholders := make([]string, 0, len(filter.ForkDigests))
+		for _, digest := range filter.ForkDigests {
+			placeholders = append(placeholders, "?")
+			args = append(args, digest.String())
+		}
+		conditions = append(conditions, "fork_digest IN ("+strings.Join(placeholders, ",")+")")
+	}
+	if !filter.Since.IsZero() {
+		conditions = append(conditions, "last_seen >= ?")
+		args = append(args, filter.Since.Unix())
+	}
+	if !filter.Until.IsZero() {
+		conditions = append(conditions, "last_seen <= ?")
+		args = append(args, filter.Until.Unix())
+	}
+	if filter.MinAttnets != nil {
+		conditions = append(conditions, "attnets_number >= ?")
+		args = append(args, *filter.MinAttnets)
+	}
+	if filter.MaxAttnets != nil {
+		conditions = append(conditions, "attnets_number <= ?")
+		args = append(args, *filter.MaxAttnets)
+	}
+	// the IPs are stored as text, so the IP network gets matched (and the limit applied) after the query
+	limit := filter.Limit
+	if filter.IPNet != nil {
+		limit = 0
+	}
+	args = append(args, sqliteLimit(limit))
+
+	enrs, err := s.queryEnrs(`
+		SELECT enr, timestamp
+		FROM enrs
+		WHERE `+strings.Join(conditions, " AND ")+`
+		ORDER BY last_seen DESC
+		LIMIT ?
+	`, args...)
+	if err != nil || filter.IPNet == nil {
+		return enrs, err
+	}
+
+	matching := make([]*discv5.EnrNode, 0)
+	for _, enr := range enrs {
+		if filter.Limit > 0 && len(matching) >= filter.Limit {
+			break
+		}
+		if filter.IPNet.Contains(enr.IP) || filter.IPNet.Contains(enr.IP6) {
+			matching = append(matching, enr)
+		}
+	}
+	return matching, nil
+}
+
+func (s *SQLiteStore) GetForkStats(filter *EnrFilter) ([]ForkStats, error) {
+	enrs, err := s.GetEnrs(filter.unlimited())
+	if err != nil {
+		return nil, err
+	}
+	return countForks(enrs), nil
+}
+
+func (s *SQLiteStore) GetSubnetStats(filter *EnrFilter) (*SubnetStats, error) {
+	enrs, err := s.GetEnrs(filter.unlimited())
+	if err != nil {
+		return nil, err
+	}
+	return countSubnets(enrs), nil
+}
+
+// sqliteLimit returns the LIMIT of a query, where -1 doesn't limit the rows
+func sqliteLimit(limit int) int {
+	if limit > 0 {
+		return limit
+	}
+	return -1
+}
+
+// queryEnrs rebuilds the ENRs (enr, timestamp) returned by the query
+func (s *SQLiteStore) queryEnrs(query string, args ...interface{}) ([]*discv5.EnrNode, error) {
+	rows, err := s.db.QueryContext(s.ctx, query, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to read the enrs")
+	}
+	defer rows.Close()
+
+	enrs := make([]*discv5.EnrNode, 0)
+	for rows.Next() {
+		var record string
+		var timestamp int64
+		if err := rows.Scan(&record, &timestamp); err != nil {
+			return nil, errors.Wrap(err, "unable to read the enrs")
+		}
+		enr, err := discv5.ParseEnr(record)
+		if err != nil {
+			log.Warnf("skipping invalid stored enr %s - %s", record, err.Error())
+			continue
+		}
+		enr.Timestamp = time.Unix(timestamp, 0)
+		enrs = append(enrs, enr)
+	}
+	return enrs, rows.Err()
+}
+
+func (s *SQLiteStore) Close() {
+	if err := s.db.Close(); err != nil {
+		log.Error(errors.Wrap(err, "unable to close the sqlite db"))
+	}
+}
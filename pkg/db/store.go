@@ -0,0 +1,229 @@
+package db
+
+import (
+	"context"
+	"net"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/migalabs/eth-light-crawler/pkg/discv5"
+	"github.com/pkg/errors"
+	"github.com/protolambda/zrnt/eth2/beacon/common"
+)
+
+// prefixes of the db-endpoint that select the storage backend
+const (
+	PostgresPrefix   = "postgres://"
+	PostgresqlPrefix = "postgresql://"
+	SQLitePrefix     = "sqlite://"
+	MemoryPrefix     = "memory://"
+)
+
+var ErrNotFound = errors.New("not found")
+
+// Store is the storage backend where the crawler keeps the discovered nodes.
+// Writes can be asynchronous (i.e. batched in the background), in which case
+// their errors get logged instead of returned
+type Store interface {
+	// UpsertEnr stores the ENR, or updates the stored one if the given ENR has a higher seq number,
+	// bumping the last time that the node was seen
+	UpsertEnr(enr *discv5.EnrNode) error
+	// UpsertForeignEnr does the same for the nodes that don't match the tracked fork digests
+	UpsertForeignEnr(enr *ForeignEnr) error
+	// UpsertImportedEnr does the same for the ENRs collected from other sources, tagging them with their source
+	UpsertImportedEnr(enr *ImportedEnr) error
+	// InsertEnrHistory records an observation of a version (node_id, seq) of an ENR
+	InsertEnrHistory(enr *EnrHistory) error
+	// InsertRoutingTable records the neighbors that a node returned when walking its k-buckets
+	InsertRoutingTable(rt *RoutingTable) error
+	// UpsertPingResult aggregates the outcome of a liveness probe
+	UpsertPingResult(result *PingResult) error
+
+	// InsertCrawl records the start of a crawl, setting its ID (synchronous)
+	InsertCrawl(crawl *Crawl) error
+	// UpdateCrawl records the end and the results of the crawl (synchronous)
+	UpdateCrawl(crawl *Crawl) error
+
+	// GetEnrSeqs returns the seq number of the stored ENRs (node_id -> seq),
+	// limited to the most recently updated limit nodes (0 = all of them)
+	GetEnrSeqs(limit int) (map[enode.ID]uint64, error)
+	// GetEnr returns the stored ENR of the node, or ErrNotFound
+	GetEnr(id enode.ID) (*discv5.EnrNode, error)
+	// GetEnrs returns the stored ENRs that match the filter, the most recently seen first
+	GetEnrs(filter *EnrFilter) ([]*discv5.EnrNode, error)
+	// GetForkStats counts the stored nodes that match the filter by fork digest, the most popular first
+	// (the limit of the filter doesn't apply to the stats)
+	GetForkStats(filter *EnrFilter) ([]ForkStats, error)
+	// GetSubnetStats counts the stored nodes that match the filter by attestation and sync subnet
+	GetSubnetStats(filter *EnrFilter) (*SubnetStats, error)
+
+	// Close flushes the pending writes and releases the backend
+	Close()
+}
+
+// EnrFilter narrows down the ENRs returned by the store (the zero value matches all of them)
+type EnrFilter struct {
+	// only the nodes advertising one of the fork digests (any if empty)
+	ForkDigests []common.ForkDigest
+	// only the nodes last seen within the time window (unbounded if zero)
+	Since time.Time
+	Until time.Time
+	// only the nodes whose IPv4 or IPv6 address is within the network (any if nil)
+	IPNet *net.IPNet
+	// only the nodes subscribed to at least/at most the given number of attnets (unbounded if nil)
+	MinAttnets *int
+	MaxAttnets *int
+	// max number of returned nodes (0 = no limit)
+	Limit int
+}
+
+func (f *EnrFilter) matches(enr *discv5.EnrNode, lastSeen time.Time) bool {
+	// the nodes that were never seen (i.e. imported) are out of any time window
+	if (!f.Since.IsZero() || !f.Until.IsZero()) && lastSeen.IsZero() {
+		return false
+	}
+	if !f.Since.IsZero() && lastSeen.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && lastSeen.After(f.Until) {
+		return false
+	}
+	if f.IPNet != nil && !f.IPNet.Contains(enr.IP) && !f.IPNet.Contains(enr.IP6) {
+		return false
+	}
+	if f.MinAttnets != nil && enr.Attnets.NetNumber < *f.MinAttnets {
+		return false
+	}
+	if f.MaxAttnets != nil && enr.Attnets.NetNumber > *f.MaxAttnets {
+		return false
+	}
+	if len(f.ForkDigests) == 0 {
+		return true
+	}
+	for _, digest := range f.ForkDigests {
+		if digest == enr.Eth2Data.ForkDigest {
+			return true
+		}
+	}
+	return false
+}
+
+// ForkStats is the number of nodes advertising a fork digest
+type ForkStats struct {
+	ForkDigest common.ForkDigest
+	Nodes      int
+}
+
+// SubnetStats is the number of nodes subscribed to each attestation and sync subnet
+type SubnetStats struct {
+	// total number of counted nodes
+	Nodes    int
+	Attnets  [discv5.AttnetsSize]int
+	Syncnets [discv5.SyncnetsSize]int
+}
+
+// unlimited returns a copy of the filter without the limit of returned nodes
+func (f *EnrFilter) unlimited() *EnrFilter {
+	unlimited := *f
+	unlimited.Limit = 0
+	return &unlimited
+}
+
+// countForks aggregates the fork stats of the given ENRs, for the stores that can't do it in SQL
+func countForks(enrs []*discv5.EnrNode) []ForkStats {
+	counts := make(map[common.ForkDigest]int)
+	for _, enr := range enrs {
+		counts[enr.Eth2Data.ForkDigest]++
+	}
+	stats := make([]ForkStats, 0, len(counts))
+	for digest, nodes := range counts {
+		stats = append(stats, ForkStats{ForkDigest: digest, Nodes: nodes})
+	}
+	sortForkStats(stats)
+	return stats
+}
+
+// sortForkStats sorts the fork digests by number of nodes (descending) and digest
+func sortForkStats(stats []ForkStats) {
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Nodes != stats[j].Nodes {
+			return stats[i].Nodes > stats[j].Nodes
+		}
+		return stats[i].ForkDigest.String() < stats[j].ForkDigest.String()
+	})
+}
+
+// countSubnets aggregates the subnet stats of the given ENRs, for the stores that can't do it in SQL
+func countSubnets(enrs []*discv5.EnrNode) *SubnetStats {
+	stats := new(SubnetStats)
+	for _, enr := range enrs {
+		stats.Nodes++
+		countBits(stats.Attnets[:], enr.Attnets.Raw[:])
+		countBits(stats.Syncnets[:], enr.Syncnets.Raw[:])
+	}
+	return stats
+}
+
+// countBits adds the set bits of the SSZ bitvector (little-endian) to the per-subnet counters
+func countBits(counters []int, bitvector []byte) {
+	for i := range counters {
+		if i/8 < len(bitvector) && (bitvector[i/8]>>(i%8))&1 == 1 {
+			counters[i]++
+		}
+	}
+}
+
+// NewStore opens the storage backend selected by the prefix of the endpoint:
+// postgres:// (or postgresql://), sqlite://<path> or memory://
+func NewStore(
+	ctx context.Context,
+	endpoint string,
+	reset bool,
+	persisters int,
+	batchSize int,
+	flushInterval time.Duration) (Store, error) {
+
+	switch {
+	case strings.HasPrefix(endpoint, PostgresPrefix), strings.HasPrefix(endpoint, PostgresqlPrefix):
+		dbClient, err := NewDBClient(ctx, endpoint, true, reset, persisters, batchSize, flushInterval)
+		if err != nil {
+			return nil, err
+		}
+		return dbClient, nil
+	case strings.HasPrefix(endpoint, SQLitePrefix):
+		sqliteStore, err := NewSQLiteStore(ctx, strings.TrimPrefix(endpoint, SQLitePrefix), reset)
+		if err != nil {
+			return nil, err
+		}
+		return sqliteStore, nil
+	case strings.HasPrefix(endpoint, MemoryPrefix):
+		return NewMemoryStore(), nil
+	default:
+		return nil, errors.Errorf("unknown db-endpoint %s, expected postgres://, sqlite:// or memory://", endpoint)
+	}
+}
+
+// NewReadOnlyStore opens the storage backend of the endpoint to read the crawled data (i.e. to serve or export it).
+// Unlike NewStore, it doesn't migrate the postgres schema, refusing to read it if it isn't at the latest version
+func NewReadOnlyStore(
+	ctx context.Context,
+	endpoint string,
+	persisters int,
+	batchSize int,
+	flushInterval time.Duration) (Store, error) {
+
+	if !strings.HasPrefix(endpoint, PostgresPrefix) && !strings.HasPrefix(endpoint, PostgresqlPrefix) {
+		return NewStore(ctx, endpoint, false, persisters, batchSize, flushInterval)
+	}
+	dbClient, err := NewDBClient(ctx, endpoint, false, false, persisters, batchSize, flushInterval)
+	if err != nil {
+		return nil, err
+	}
+	if err := dbClient.checkSchemaVersion(); err != nil {
+		dbClient.Close()
+		return nil, err
+	}
+	return dbClient, nil
+}
@@ -0,0 +1,319 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/migalabs/eth-light-crawler/pkg/discv5"
+)
+
+func TestCountBits(t *testing.T) {
+	counters := make([]int, discv5.SyncnetsSize)
+	countBits(counters, []byte{0x03})
+	countBits(counters, []byte{0x06})
+	// a missing bitvector doesn't count any subnet
+	countBits(counters, nil)
+
+	expected := []int{1, 2, 1, 0}
+	for i := range expected {
+		if counters[i] != expected[i] {
+			t.Fatalf("counted %v, expected %v", counters, expected)
+		}
+	}
+}
+
+// storedEnrRow is what the store keeps of a node, beyond the ENR itself
+type storedEnrRow struct {
+	seq      uint64
+	lastSeen time.Time
+	source   string
+}
+
+// storedVersionRow is an observation of a version of an ENR in the history
+type storedVersionRow struct {
+	firstSeen time.Time
+	lastSeen  time.Time
+}
+
+// storeBackend opens an empty store and reads back the rows that the Store interface doesn't return
+type storeBackend struct {
+	name    string
+	open    func(t *testing.T) Store
+	enr     func(t *testing.T, store Store, id enode.ID) (storedEnrRow, bool)
+	history func(t *testing.T, store Store, id enode.ID) map[int64]storedVersionRow
+}
+
+var storeBackends = []storeBackend{
+	{
+		name: "memory",
+		open: func(t *testing.T) Store {
+			return NewMemoryStore()
+		},
+		enr: func(t *testing.T, store Store, id enode.ID) (storedEnrRow, bool) {
+			stored, ok := store.(*MemoryStore).enrs[id]
+			if !ok {
+				return storedEnrRow{}, false
+			}
+			return storedEnrRow{seq: stored.enr.Seq, lastSeen: stored.lastSeen, source: stored.source}, true
+		},
+		history: func(t *testing.T, store Store, id enode.ID) map[int64]storedVersionRow {
+			rows := make(map[int64]storedVersionRow)
+			for version, stored := range store.(*MemoryStore).history {
+				if version.id == id {
+					rows[version.crawlID] = storedVersionRow{firstSeen: stored.firstSeen, lastSeen: stored.lastSeen}
+				}
+			}
+			return rows
+		},
+	},
+	{
+		name: "sqlite",
+		open: func(t *testing.T) Store {
+			store, err := NewSQLiteStore(context.Background(), filepath.Join(t.TempDir(), "crawler.db"), false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return store
+		},
+		enr: func(t *testing.T, store Store, id enode.ID) (storedEnrRow, bool) {
+			var row storedEnrRow
+			var lastSeen sql.NullInt64
+			err := store.(*SQLiteStore).db.QueryRow(`SELECT seq, last_seen, source FROM enrs WHERE node_id=?`, id.String()).
+				Scan(&row.seq, &lastSeen, &row.source)
+			if err == sql.ErrNoRows {
+				return row, false
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if lastSeen.Valid {
+				row.lastSeen = time.Unix(lastSeen.Int64, 0)
+			}
+			return row, true
+		},
+		history: func(t *testing.T, store Store, id enode.ID) map[int64]storedVersionRow {
+			rows, err := store.(*SQLiteStore).db.Query(`SELECT COALESCE(crawl_id, 0), first_seen, last_seen FROM enr_history WHERE node_id=?`, id.String())
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer rows.Close()
+			versions := make(map[int64]storedVersionRow)
+			for rows.Next() {
+				var crawlID, firstSeen, lastSeen int64
+				if err := rows.Scan(&crawlID, &firstSeen, &lastSeen); err != nil {
+					t.Fatal(err)
+				}
+				if _, ok := versions[crawlID]; ok {
+					t.Errorf("duplicated version of crawl %d", crawlID)
+				}
+				versions[crawlID] = storedVersionRow{firstSeen: time.Unix(firstSeen, 0), lastSeen: time.Unix(lastSeen, 0)}
+			}
+			return versions
+		},
+	},
+}
+
+// testEnr returns a version of the ENR of the node seen at the given unix time
+func testEnr(t *testing.T, id enode.ID, seq uint64, seenAt int64) *discv5.EnrNode {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	enr := discv5.NewEnrNode(id)
+	enr.Seq = seq
+	enr.Timestamp = time.Unix(seenAt, 0)
+	enr.Pubkey = &key.PublicKey
+	enr.Enr = "enr:-test"
+	return enr
+}
+
+// upserted ENR of the store tests: discovered by the crawler, or imported from the given source
+type testUpsert struct {
+	seq    uint64
+	seenAt int64
+	source string
+}
+
+func TestStoreUpsertEnr(t *testing.T) {
+	tests := []struct {
+		name     string
+		upserts  []testUpsert
+		expected storedEnrRow
+	}{
+		{
+			name:     "new node",
+			upserts:  []testUpsert{{seq: 1, seenAt: 100}},
+			expected: storedEnrRow{seq: 1, lastSeen: time.Unix(100, 0), source: CrawlerSource},
+		},
+		{
+			name:     "last_seen moves forward",
+			upserts:  []testUpsert{{seq: 1, seenAt: 100}, {seq: 1, seenAt: 200}},
+			expected: storedEnrRow{seq: 1, lastSeen: time.Unix(200, 0), source: CrawlerSource},
+		},
+		{
+			name:     "last_seen never moves back",
+			upserts:  []testUpsert{{seq: 1, seenAt: 200}, {seq: 1, seenAt: 100}},
+			expected: storedEnrRow{seq: 1, lastSeen: time.Unix(200, 0), source: CrawlerSource},
+		},
+		{
+			name:     "newer version seen earlier",
+			upserts:  []testUpsert{{seq: 1, seenAt: 200}, {seq: 2, seenAt: 100}},
+			expected: storedEnrRow{seq: 2, lastSeen: time.Unix(200, 0), source: CrawlerSource},
+		},
+		{
+			name:     "higher seq updates the ENR",
+			upserts:  []testUpsert{{seq: 1, seenAt: 100}, {seq: 3, seenAt: 200}},
+			expected: storedEnrRow{seq: 3, lastSeen: time.Unix(200, 0), source: CrawlerSource},
+		},
+		{
+			name:     "lower seq only bumps last_seen",
+			upserts:  []testUpsert{{seq: 3, seenAt: 100}, {seq: 2, seenAt: 200}},
+			expected: storedEnrRow{seq: 3, lastSeen: time.Unix(200, 0), source: CrawlerSource},
+		},
+		{
+			name:     "imported node was never seen",
+			upserts:  []testUpsert{{seq: 1, seenAt: 100, source: "bootnodes"}},
+			expected: storedEnrRow{seq: 1, source: "bootnodes"},
+		},
+		{
+			name:     "imported node seen with the same seq",
+			upserts:  []testUpsert{{seq: 1, seenAt: 100, source: "bootnodes"}, {seq: 1, seenAt: 200}},
+			expected: storedEnrRow{seq: 1, lastSeen: time.Unix(200, 0), source: "bootnodes"},
+		},
+		{
+			name:     "imported node seen with a higher seq flips to discv5",
+			upserts:  []testUpsert{{seq: 1, seenAt: 100, source: "bootnodes"}, {seq: 2, seenAt: 200}},
+			expected: storedEnrRow{seq: 2, lastSeen: time.Unix(200, 0), source: CrawlerSource},
+		},
+		{
+			name:     "import of a higher seq keeps last_seen",
+			upserts:  []testUpsert{{seq: 1, seenAt: 100}, {seq: 2, seenAt: 300, source: "bootnodes"}},
+			expected: storedEnrRow{seq: 2, lastSeen: time.Unix(100, 0), source: "bootnodes"},
+		},
+		{
+			name:     "import of a lower seq is ignored",
+			upserts:  []testUpsert{{seq: 2, seenAt: 100}, {seq: 1, seenAt: 300, source: "bootnodes"}},
+			expected: storedEnrRow{seq: 2, lastSeen: time.Unix(100, 0), source: CrawlerSource},
+		},
+	}
+
+	for _, backend := range storeBackends {
+		for _, test := range tests {
+			t.Run(backend.name+"/"+test.name, func(t *testing.T) {
+				store := backend.open(t)
+				defer store.Close()
+
+				id := enode.ID{1}
+				for _, upsert := range test.upserts {
+					enr := testEnr(t, id, upsert.seq, upsert.seenAt)
+					var err error
+					if upsert.source == "" {
+						err = store.UpsertEnr(enr)
+					} else {
+						err = store.UpsertImportedEnr(&ImportedEnr{EnrNode: enr, Source: upsert.source})
+					}
+					if err != nil {
+						t.Fatal(err)
+					}
+				}
+
+				row, ok := backend.enr(t, store, id)
+				if !ok {
+					t.Fatal("the node wasn't stored")
+				}
+				if row.seq != test.expected.seq || row.source != test.expected.source || !row.lastSeen.Equal(test.expected.lastSeen) {
+					t.Fatalf("stored seq %d, last_seen %s, source %s, expected seq %d, last_seen %s, source %s",
+						row.seq, row.lastSeen, row.source, test.expected.seq, test.expected.lastSeen, test.expected.source)
+				}
+			})
+		}
+	}
+}
+
+func TestStoreEnrHistory(t *testing.T) {
+	// observations of the version 1 of the node
+	type observation struct {
+		crawl  int
+		seenAt int64
+	}
+	tests := []struct {
+		name         string
+		observations []observation
+		expected     map[int64]storedVersionRow
+	}{
+		{
+			name:         "single observation",
+			observations: []observation{{crawl: 1, seenAt: 100}},
+			expected:     map[int64]storedVersionRow{1: {time.Unix(100, 0), time.Unix(100, 0)}},
+		},
+		{
+			name:         "deduplicated within a crawl",
+			observations: []observation{{crawl: 1, seenAt: 100}, {crawl: 1, seenAt: 150}, {crawl: 1, seenAt: 200}},
+			expected:     map[int64]storedVersionRow{1: {time.Unix(100, 0), time.Unix(200, 0)}},
+		},
+		{
+			name:         "kept per crawl",
+			observations: []observation{{crawl: 1, seenAt: 100}, {crawl: 2, seenAt: 300}, {crawl: 1, seenAt: 200}},
+			expected: map[int64]storedVersionRow{
+				1: {time.Unix(100, 0), time.Unix(200, 0)},
+				2: {time.Unix(300, 0), time.Unix(300, 0)},
+			},
+		},
+		{
+			name:         "deduplicated without crawl",
+			observations: []observation{{crawl: 0, seenAt: 100}, {crawl: 0, seenAt: 200}, {crawl: 1, seenAt: 300}},
+			expected: map[int64]storedVersionRow{
+				0: {time.Unix(100, 0), time.Unix(200, 0)},
+				1: {time.Unix(300, 0), time.Unix(300, 0)},
+			},
+		},
+	}
+
+	for _, backend := range storeBackends {
+		for _, test := range tests {
+			t.Run(backend.name+"/"+test.name, func(t *testing.T) {
+				store := backend.open(t)
+				defer store.Close()
+
+				// the crawls referenced by the history
+				crawlIDs := []int64{0}
+				for i := 1; i <= 2; i++ {
+					crawl := &Crawl{StartTime: time.Unix(0, 0), Status: CrawlRunning}
+					if err := store.InsertCrawl(crawl); err != nil {
+						t.Fatal(err)
+					}
+					crawlIDs = append(crawlIDs, crawl.ID)
+				}
+
+				id := enode.ID{1}
+				for _, observation := range test.observations {
+					enr := testEnr(t, id, 1, observation.seenAt)
+					err := store.InsertEnrHistory(&EnrHistory{EnrNode: enr, CrawlID: crawlIDs[observation.crawl]})
+					if err != nil {
+						t.Fatal(err)
+					}
+				}
+
+				versions := backend.history(t, store, id)
+				if len(versions) != len(test.expected) {
+					t.Fatalf("stored %d versions, expected %d", len(versions), len(test.expected))
+				}
+				for crawl, expected := range test.expected {
+					version, ok := versions[crawlIDs[crawl]]
+					if !ok {
+						t.Fatalf("missing the version of crawl %d", crawl)
+					}
+					if !version.firstSeen.Equal(expected.firstSeen) || !version.lastSeen.Equal(expected.lastSeen) {
+						t.Errorf("crawl %d: version seen from %s to %s, expected from %s to %s",
+							crawl, version.firstSeen, version.lastSeen, expected.firstSeen, expected.lastSeen)
+					}
+				}
+			})
+		}
+	}
+}
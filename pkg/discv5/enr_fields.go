@@ -0,0 +1,64 @@
+package discv5
+
+import (
+	"encoding/hex"
+	"net"
+
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// ParseEnrFields decodes all the key/value pairs of the node's record.
+// Well-known keys are decoded into a readable representation, while the rest of them
+// are kept as the hex encoding of their raw RLP value, so that no entry gets lost
+func ParseEnrFields(node enode.Node) map[string]interface{} {
+	fields := make(map[string]interface{})
+
+	// the elements are [seq, k1, v1, k2, v2, ...]
+	elements := node.Record().AppendElements(nil)
+	for i := 1; i+1 < len(elements); i += 2 {
+		key, ok := elements[i].(string)
+		if !ok {
+			continue
+		}
+		rawValue, ok := elements[i+1].(rlp.RawValue)
+		if !ok {
+			continue
+		}
+		fields[key] = decodeEnrValue(key, rawValue)
+	}
+	return fields
+}
+
+func decodeEnrValue(key string, rawValue rlp.RawValue) interface{} {
+	switch key {
+	case "id":
+		var id string
+		if rlp.DecodeBytes(rawValue, &id) == nil {
+			return id
+		}
+	case "ip", "ip6":
+		var ip net.IP
+		if rlp.DecodeBytes(rawValue, &ip) == nil {
+			return ip.String()
+		}
+	case "tcp", "udp", "tcp6", "udp6", "quic", "quic6":
+		var port uint16
+		if rlp.DecodeBytes(rawValue, &port) == nil {
+			return port
+		}
+	case "client":
+		var client []string
+		if rlp.DecodeBytes(rawValue, &client) == nil {
+			return client
+		}
+	default:
+		// byte strings (secp256k1, eth2, attnets, syncnets, ...)
+		var bytesValue []byte
+		if rlp.DecodeBytes(rawValue, &bytesValue) == nil {
+			return "0x" + hex.EncodeToString(bytesValue)
+		}
+	}
+	// lists or values that couldn't be decoded (eth, les, snap, ...)
+	return "0x" + hex.EncodeToString(rawValue)
+}
@@ -1,15 +1,18 @@
 package discv5
 
 import (
+	"bytes"
 	"crypto/ecdsa"
-	"encoding/binary"
 	"math/bits"
 	"net"
 	"time"
 
 	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethereum/go-ethereum/p2p/enr"
 	"github.com/migalabs/armiarma/src/utils"
+	"github.com/pkg/errors"
 	"github.com/protolambda/zrnt/eth2/beacon/common"
+	"github.com/protolambda/ztyp/codec"
 )
 
 type EnrNode struct {
@@ -19,9 +22,17 @@ type EnrNode struct {
 	Seq       uint64
 	UDP       int
 	TCP       int
+	IP6       net.IP
+	UDP6      int
+	TCP6      int
 	Pubkey    *ecdsa.PublicKey
 	Eth2Data  *common.Eth2Data
 	Attnets   *Attnets
+	Syncnets  *Syncnets
+	// textual representation of the record ("enr:" + base64 RLP)
+	Enr string
+	// all the key/value pairs of the record
+	Fields map[string]interface{}
 }
 
 func NewEnrNode(nodeID enode.ID) *EnrNode {
@@ -32,9 +43,124 @@ func NewEnrNode(nodeID enode.ID) *EnrNode {
 		Pubkey:    new(ecdsa.PublicKey),
 		Eth2Data:  new(common.Eth2Data),
 		Attnets:   new(Attnets),
+		Syncnets:  new(Syncnets),
+		Fields:    make(map[string]interface{}),
 	}
 }
 
+// EntryError is an entry of the record that couldn't be parsed (the node keeps its empty value instead)
+type EntryError struct {
+	Entry string
+	Err   error
+}
+
+func (e *EntryError) Error() string {
+	return e.Entry + " parsing error: " + e.Err.Error()
+}
+
+// ParseEnrNode extracts from the record of the node all the fields that the crawler indexes.
+// The eth2 entries that can't be parsed are returned along with the node, so that only the crawler
+// reports them (and not every read of the stored records)
+func ParseEnrNode(node *enode.Node) (*EnrNode, []*EntryError) {
+	var entryErrs []*EntryError
+
+	// Retrieve the Fork Digest and the attestnets
+	eth2Data, ok, err := utils.ParseNodeEth2Data(*node)
+	if !ok {
+		eth2Data = new(common.Eth2Data)
+	} else {
+		if err != nil {
+			entryErrs = append(entryErrs, &EntryError{Entry: "eth2", Err: err})
+			eth2Data = new(common.Eth2Data)
+		}
+	}
+
+	attnets, ok, err := ParseAttnets(*node)
+	if !ok {
+		attnets = new(Attnets)
+	} else {
+		if err != nil {
+			entryErrs = append(entryErrs, &EntryError{Entry: "attnets", Err: err})
+		}
+	}
+	syncnets, ok, err := ParseSyncnets(*node)
+	if !ok {
+		syncnets = new(Syncnets)
+	} else {
+		if err != nil {
+			entryErrs = append(entryErrs, &EntryError{Entry: "syncnets", Err: err})
+		}
+	}
+
+	// create a new ENR node with all the fields from the CL network
+	enrNode := NewEnrNode(node.ID())
+	enrNode.Seq = node.Seq()
+	enrNode.IP, enrNode.UDP, enrNode.TCP = ParseIPv4(*node)
+	enrNode.IP6, enrNode.UDP6, enrNode.TCP6 = ParseIPv6(*node)
+	enrNode.Pubkey = node.Pubkey()
+	enrNode.Eth2Data = eth2Data
+	enrNode.Attnets = attnets
+	enrNode.Syncnets = syncnets
+	enrNode.Enr = node.String()
+	enrNode.Fields = ParseEnrFields(*node)
+	return enrNode, entryErrs
+}
+
+// ParseEnr decodes the textual representation of a record ("enr:" + base64 RLP)
+// and extracts its fields, i.e. to rebuild the nodes stored by the crawler
+// (the eth2 entries that can't be parsed are silently left empty)
+func ParseEnr(record string) (*EnrNode, error) {
+	node, err := enode.Parse(enode.ValidSchemes, record)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to parse the enr")
+	}
+	enrNode, _ := ParseEnrNode(node)
+	return enrNode, nil
+}
+
+// ParseIPv4 returns the IPv4 address and ports (ip, udp, tcp entries) advertised by the node, if any
+// (node.IP() can't be used, as it returns the IPv6 endpoint when it is preferred over the IPv4 one)
+func ParseIPv4(node enode.Node) (ip net.IP, udp int, tcp int) {
+	var ip4 enr.IPv4
+	var udp4 enr.UDP
+	var tcp4 enr.TCP
+	if node.Load(&ip4) == nil {
+		ip = net.IP(ip4)
+	}
+	node.Load(&udp4)
+	node.Load(&tcp4)
+	return ip, int(udp4), int(tcp4)
+}
+
+// ParseIPv6 returns the IPv6 address and ports (ip6, udp6, tcp6 entries) advertised by the node, if any
+func ParseIPv6(node enode.Node) (ip net.IP, udp int, tcp int) {
+	var ip6 enr.IPv6
+	var udp6 enr.UDP6
+	var tcp6 enr.TCP6
+	if node.Load(&ip6) == nil {
+		ip = net.IP(ip6)
+	}
+	node.Load(&udp6)
+	node.Load(&tcp6)
+	return ip, int(udp6), int(tcp6)
+}
+
+// NewEth2ENREntry serializes the given Eth2Data into the "eth2" entry of an ENR
+func NewEth2ENREntry(eth2Data *common.Eth2Data) (utils.Eth2ENREntry, error) {
+	var buf bytes.Buffer
+	err := eth2Data.Serialize(codec.NewEncodingWriter(&buf))
+	if err != nil {
+		return nil, err
+	}
+	return utils.Eth2ENREntry(buf.Bytes()), nil
+}
+
+// number of attestation and sync committee subnets (the length of the attnets and syncnets bitvectors)
+const (
+	AttnetsSize  = 64
+	SyncnetsSize = 4
+)
+
 type Attnets struct {
 	Raw       utils.AttnetsENREntry
 	NetNumber int
@@ -46,9 +172,12 @@ func ParseAttnets(node enode.Node) (attnets *Attnets, exists bool, err error) {
 	attEntry := new(utils.AttnetsENREntry)
 
 	err = node.Load(attEntry)
-	if err != nil {
+	if enr.IsNotFound(err) {
 		return att, false, nil
 	}
+	if err != nil {
+		return att, true, err
+	}
 	att.Raw = *attEntry
 
 	// count the number of bits in the Attnets
@@ -56,7 +185,46 @@ func ParseAttnets(node enode.Node) (attnets *Attnets, exists bool, err error) {
 	return att, true, nil
 }
 
+// SyncnetsENREntry is the "syncnets" entry of the ENR, the 4-bit bitvector
+// of the sync committee subnets that the node is subscribed to (Altair)
+type SyncnetsENREntry []byte
+
+func (s SyncnetsENREntry) ENRKey() string {
+	return "syncnets"
+}
+
+type Syncnets struct {
+	Raw       SyncnetsENREntry
+	NetNumber int
+}
+
+func ParseSyncnets(node enode.Node) (syncnets *Syncnets, exists bool, err error) {
+	sync := new(Syncnets)
+
+	syncEntry := new(SyncnetsENREntry)
+
+	err = node.Load(syncEntry)
+	if enr.IsNotFound(err) {
+		return sync, false, nil
+	}
+	if err != nil {
+		return sync, true, err
+	}
+	// the 4-bit bitvector is serialized as a single byte
+	if len(*syncEntry) != 1 {
+		return sync, true, errors.Errorf("invalid syncnets length %d, expected 1 byte", len(*syncEntry))
+	}
+	sync.Raw = *syncEntry
+
+	// count the number of bits in the Syncnets (leaving out the padding bits of the byte)
+	sync.NetNumber = CountBits([]byte{sync.Raw[0] & (1<<SyncnetsSize - 1)})
+	return sync, true, nil
+}
+
 func CountBits(byteArr []byte) int {
-	rawInt := binary.BigEndian.Uint64(byteArr)
-	return bits.OnesCount64(rawInt)
+	count := 0
+	for _, b := range byteArr {
+		count += bits.OnesCount8(b)
+	}
+	return count
 }
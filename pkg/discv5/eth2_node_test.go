@@ -0,0 +1,187 @@
+package discv5
+
+import (
+	"net"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethereum/go-ethereum/p2p/enr"
+)
+
+// testNode signs a record with the given entries
+func testNode(t *testing.T, entries ...enr.Entry) *enode.Node {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var record enr.Record
+	for _, entry := range entries {
+		record.Set(entry)
+	}
+	if err := enode.SignV4(&record, key); err != nil {
+		t.Fatal(err)
+	}
+	node, err := enode.New(enode.ValidSchemes, &record)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return node
+}
+
+func TestCountBits(t *testing.T) {
+	tests := []struct {
+		bitvector []byte
+		count     int
+	}{
+		{bitvector: nil, count: 0},
+		{bitvector: []byte{0x00}, count: 0},
+		{bitvector: []byte{0x0f}, count: 4},
+		{bitvector: []byte{0x81, 0x01}, count: 3},
+		{bitvector: []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}, count: 64},
+	}
+
+	for _, test := range tests {
+		if count := CountBits(test.bitvector); count != test.count {
+			t.Errorf("CountBits(%x) = %d, expected %d", test.bitvector, count, test.count)
+		}
+	}
+}
+
+func TestParseSyncnets(t *testing.T) {
+	tests := []struct {
+		name      string
+		entries   []enr.Entry
+		exists    bool
+		netNumber int
+		valid     bool
+	}{
+		{
+			name:   "missing",
+			exists: false,
+			valid:  true,
+		},
+		{
+			name:      "empty bitvector",
+			entries:   []enr.Entry{SyncnetsENREntry{0x00}},
+			exists:    true,
+			netNumber: 0,
+			valid:     true,
+		},
+		{
+			name:      "two subnets",
+			entries:   []enr.Entry{SyncnetsENREntry{0x05}},
+			exists:    true,
+			netNumber: 2,
+			valid:     true,
+		},
+		{
+			name:      "all subnets",
+			entries:   []enr.Entry{SyncnetsENREntry{0x0f}},
+			exists:    true,
+			netNumber: 4,
+			valid:     true,
+		},
+		{
+			name:      "padding bits",
+			entries:   []enr.Entry{SyncnetsENREntry{0xf1}},
+			exists:    true,
+			netNumber: 1,
+			valid:     true,
+		},
+		{
+			name:    "too long",
+			entries: []enr.Entry{SyncnetsENREntry{0x01, 0x00}},
+			exists:  true,
+			valid:   false,
+		},
+		{
+			name:    "empty",
+			entries: []enr.Entry{SyncnetsENREntry{}},
+			exists:  true,
+			valid:   false,
+		},
+		{
+			name:    "not a bitvector",
+			entries: []enr.Entry{enr.WithEntry("syncnets", []uint{1, 2})},
+			exists:  true,
+			valid:   false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			syncnets, exists, err := ParseSyncnets(*testNode(t, test.entries...))
+			if exists != test.exists {
+				t.Fatalf("exists = %t, expected %t", exists, test.exists)
+			}
+			if (err == nil) != test.valid {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if test.valid && syncnets.NetNumber != test.netNumber {
+				t.Fatalf("NetNumber = %d, expected %d", syncnets.NetNumber, test.netNumber)
+			}
+		})
+	}
+}
+
+func TestParseEnrNodeEndpoints(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []enr.Entry
+		ip      string
+		udp     int
+		tcp     int
+		ip6     string
+		udp6    int
+		tcp6    int
+	}{
+		{
+			name:    "IPv4 only",
+			entries: []enr.Entry{enr.IPv4(net.ParseIP("1.2.3.4")), enr.UDP(9000), enr.TCP(9001)},
+			ip:      "1.2.3.4",
+			udp:     9000,
+			tcp:     9001,
+		},
+		{
+			name:    "IPv6 only",
+			entries: []enr.Entry{enr.IPv6(net.ParseIP("2001:db8::1")), enr.UDP6(9100), enr.TCP6(9101)},
+			ip6:     "2001:db8::1",
+			udp6:    9100,
+			tcp6:    9101,
+		},
+		{
+			name: "private IPv4 and public IPv6",
+			entries: []enr.Entry{
+				enr.IPv4(net.ParseIP("192.168.1.10")), enr.UDP(9000), enr.TCP(9001),
+				enr.IPv6(net.ParseIP("2001:db8::1")), enr.UDP6(9100), enr.TCP6(9101),
+			},
+			ip:   "192.168.1.10",
+			udp:  9000,
+			tcp:  9001,
+			ip6:  "2001:db8::1",
+			udp6: 9100,
+			tcp6: 9101,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			enrNode, _ := ParseEnrNode(testNode(t, test.entries...))
+			if ip := ipString(enrNode.IP); ip != test.ip || enrNode.UDP != test.udp || enrNode.TCP != test.tcp {
+				t.Errorf("IPv4 endpoint %s udp %d tcp %d, expected %s udp %d tcp %d", ip, enrNode.UDP, enrNode.TCP, test.ip, test.udp, test.tcp)
+			}
+			if ip6 := ipString(enrNode.IP6); ip6 != test.ip6 || enrNode.UDP6 != test.udp6 || enrNode.TCP6 != test.tcp6 {
+				t.Errorf("IPv6 endpoint %s udp %d tcp %d, expected %s udp %d tcp %d", ip6, enrNode.UDP6, enrNode.TCP6, test.ip6, test.udp6, test.tcp6)
+			}
+		})
+	}
+}
+
+// ipString returns the textual IP, or an empty string if the node doesn't advertise it
+func ipString(ip net.IP) string {
+	if ip == nil {
+		return ""
+	}
+	return ip.String()
+}
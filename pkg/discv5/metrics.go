@@ -0,0 +1,42 @@
+package discv5
+
+import (
+	"github.com/migalabs/eth-light-crawler/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	nodesFound = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "discovery",
+		Name:      "nodes_found_total",
+		Help:      "Nodes returned by the discovery lookups (including repeated ones).",
+	})
+	handleQueueDepth = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "discovery",
+		Name:      "handle_queue_depth",
+		Help:      "Discovered nodes waiting for the ENR handlers.",
+	})
+	routingTableRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "discovery",
+		Name:      "routing_table_requests_total",
+		Help:      "Routing tables requested with FINDNODE during the targeted crawl, by result.",
+	}, []string{"result"})
+	pingResults = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "discovery",
+		Name:      "pings_total",
+		Help:      "Liveness probes sent to the tracked nodes, by result.",
+	}, []string{"result"})
+)
+
+// resultLabel returns the value of the result label of an operation
+func resultLabel(err error) string {
+	if err != nil {
+		return "error"
+	}
+	return "ok"
+}
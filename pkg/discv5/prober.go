@@ -0,0 +1,177 @@
+package discv5
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	log "github.com/sirupsen/logrus"
+)
+
+// number of nodes that are PINGed at the same time
+const probeWorkers = 32
+
+// PingResult is the outcome of a liveness probe to a node
+type PingResult struct {
+	NodeID    enode.ID
+	Timestamp time.Time
+	RTT       time.Duration
+	Err       error
+	// consecutive failed probes, the node is considered offline once it reaches the max failures
+	ConsecutiveFailures int
+	Offline             bool
+}
+
+type probedNode struct {
+	node     *enode.Node
+	failures int
+}
+
+// Prober periodically PINGs the nodes that it tracks to check whether they are still alive.
+// Nodes that fail maxFailures consecutive probes are reported as offline and stop being probed
+// until they get discovered (tracked) again
+type Prober struct {
+	dv5         *Discv5Service
+	interval    time.Duration
+	maxFailures int
+	handler     func(*PingResult)
+
+	m     sync.Mutex
+	nodes map[enode.ID]*probedNode
+
+	closeC chan struct{}
+	doneC  chan struct{}
+}
+
+func NewProber(dv5 *Discv5Service, interval time.Duration, maxFailures int, handler func(*PingResult)) *Prober {
+	return &Prober{
+		dv5:         dv5,
+		interval:    interval,
+		maxFailures: maxFailures,
+		handler:     handler,
+		nodes:       make(map[enode.ID]*probedNode),
+		closeC:      make(chan struct{}),
+		doneC:       make(chan struct{}),
+	}
+}
+
+// Track adds the node to the set of probed nodes (or refreshes its record)
+func (p *Prober) Track(node *enode.Node) {
+	p.m.Lock()
+	defer p.m.Unlock()
+
+	if probed, ok := p.nodes[node.ID()]; ok {
+		probed.node = node
+		return
+	}
+	p.nodes[node.ID()] = &probedNode{node: node}
+}
+
+// Run probes all the tracked nodes every interval until the prober gets closed
+func (p *Prober) Run() {
+	defer close(p.doneC)
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			p.probeRound()
+		case <-p.closeC:
+			return
+		case <-p.dv5.ctx.Done():
+			return
+		}
+	}
+}
+
+// Close stops the probing loop and waits until the ongoing round is over
+func (p *Prober) Close() {
+	close(p.closeC)
+	<-p.doneC
+}
+
+func (p *Prober) probeRound() {
+	p.m.Lock()
+	nodes := make([]*enode.Node, 0, len(p.nodes))
+	for _, probed := range p.nodes {
+		nodes = append(nodes, probed.node)
+	}
+	p.m.Unlock()
+
+	log.Debugf("probing the liveness of %d nodes", len(nodes))
+	startT := time.Now()
+
+	jobC := make(chan *enode.Node)
+	var wg sync.WaitGroup
+	var alive, failed int64
+	for worker := 0; worker < probeWorkers; worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for node := range jobC {
+				result := p.probe(node)
+				if result.Err != nil {
+					atomic.AddInt64(&failed, 1)
+				} else {
+					atomic.AddInt64(&alive, 1)
+				}
+				p.handler(result)
+			}
+		}()
+	}
+
+	interrupted := false
+sendLoop:
+	for _, node := range nodes {
+		select {
+		case jobC <- node:
+		case <-p.closeC:
+			interrupted = true
+			break sendLoop
+		}
+	}
+	close(jobC)
+	wg.Wait()
+
+	log.WithFields(log.Fields{
+		"probed":      len(nodes),
+		"alive":       alive,
+		"failed":      failed,
+		"duration":    time.Since(startT),
+		"interrupted": interrupted,
+	}).Info("liveness probing round finished")
+}
+
+func (p *Prober) probe(node *enode.Node) *PingResult {
+	result := &PingResult{
+		NodeID:    node.ID(),
+		Timestamp: time.Now(),
+	}
+	_, err := p.dv5.dv5Listener.Ping(node)
+	result.RTT = time.Since(result.Timestamp)
+	pingResults.WithLabelValues(resultLabel(err)).Inc()
+
+	p.m.Lock()
+	defer p.m.Unlock()
+	probed, ok := p.nodes[node.ID()]
+	if !ok {
+		probed = &probedNode{node: node}
+	}
+	if err != nil {
+		result.Err = err
+		probed.failures++
+	} else {
+		probed.failures = 0
+	}
+	result.ConsecutiveFailures = probed.failures
+
+	// stop probing the node once it's considered offline
+	if probed.failures >= p.maxFailures {
+		result.Offline = true
+		delete(p.nodes, node.ID())
+	}
+	return result
+}
@@ -5,10 +5,22 @@ import (
 	"crypto/ecdsa"
 	"errors"
 	"net"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	gethlog "github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/p2p/discover"
 	"github.com/ethereum/go-ethereum/p2p/enode"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// number of discovered nodes that can be waiting for the handlers before the lookups get blocked
+	handleBufferSize = 1024
+
+	// how often the discovery throughput gets logged
+	throughputInterval = 1 * time.Minute
 )
 
 type Discv5Service struct {
@@ -18,23 +30,38 @@ type Discv5Service struct {
 	dv5Listener *discover.UDPv5
 	iterator    enode.Iterator
 	enrHandler  func(*enode.Node)
+
+	// parallel lookups/FINDNODE requests and ENR handlers
+	workers    int
+	handleC    chan *enode.Node
+	handlersWG sync.WaitGroup
+	discovered uint64
+	handled    uint64
+
+	closeC    chan struct{}
+	closeOnce sync.Once
 }
 
 func NewService(
 	ctx context.Context,
+	listenIP net.IP,
 	port int,
 	privkey *ecdsa.PrivateKey,
 	ethNode *enode.LocalNode,
 	bootnodes []*enode.Node,
+	workers int,
 	enrHandler func(*enode.Node)) (*Discv5Service, error) {
 
 	if len(bootnodes) == 0 {
 		return nil, errors.New("unable to start dv5 peer discovery, no bootnodes provided")
 	}
+	if workers < 1 {
+		return nil, errors.New("the number of workers must be at least 1")
+	}
 
-	// udp address to listen
+	// udp address to listen (listening on "::" accepts both IPv4 and IPv6 traffic)
 	udpAddr := &net.UDPAddr{
-		IP:   net.IPv4zero,
+		IP:   listenIP,
 		Port: port,
 	}
 
@@ -45,10 +72,7 @@ func NewService(
 	}
 
 	// Set custom logger for the discovery5 service (Debug)
-	gethLogger := gethlog.New()
-	gethLogger.SetHandler(gethlog.FuncHandler(func(r *gethlog.Record) error {
-		return nil
-	}))
+	gethLogger := gethlog.NewLogger(gethlog.DiscardHandler())
 
 	// configuration of the discovery5
 	cfg := discover.Config{
@@ -66,7 +90,11 @@ func NewService(
 		return nil, err
 	}
 
-	iterator := dv5Listener.RandomNodes()
+	// mix several random walks so that the lookups run in parallel
+	iterator := enode.NewFairMix(0)
+	for i := 0; i < workers; i++ {
+		iterator.AddSource(dv5Listener.RandomNodes())
+	}
 
 	return &Discv5Service{
 		ctx:         ctx,
@@ -74,11 +102,15 @@ func NewService(
 		dv5Listener: dv5Listener,
 		iterator:    iterator,
 		enrHandler:  enrHandler,
+		workers:     workers,
+		handleC:     make(chan *enode.Node, handleBufferSize),
+		closeC:      make(chan struct{}),
 	}, nil
 }
 
 func (dv5 *Discv5Service) Run() {
-	// convert syncronous RandomNodes into asyc
+	dv5.startHandlers()
+	defer dv5.stopHandlers()
 
 	for {
 		// check if the context is still up
@@ -86,11 +118,77 @@ func (dv5 *Discv5Service) Run() {
 			break
 		}
 
-		if dv5.iterator.Next() {
-			newNode := dv5.iterator.Node()
-
-			dv5.enrHandler(newNode)
+		// Next() only returns false once the iterator has been closed
+		if !dv5.iterator.Next() {
+			break
 		}
+		dv5.handleNode(dv5.iterator.Node())
+	}
+}
 
+// startHandlers spawns the workers that run the ENR handler over the discovered nodes
+func (dv5 *Discv5Service) startHandlers() {
+	for worker := 0; worker < dv5.workers; worker++ {
+		dv5.handlersWG.Add(1)
+		go func() {
+			defer dv5.handlersWG.Done()
+			for node := range dv5.handleC {
+				handleQueueDepth.Set(float64(len(dv5.handleC)))
+				dv5.enrHandler(node)
+				atomic.AddUint64(&dv5.handled, 1)
+			}
+		}()
 	}
+	go dv5.logThroughput()
+}
+
+// stopHandlers waits until the handlers are done with the nodes left in the queue
+func (dv5 *Discv5Service) stopHandlers() {
+	close(dv5.handleC)
+	dv5.handlersWG.Wait()
+}
+
+// handleNode queues the node for the handlers, blocking the discovery if they can't keep up
+func (dv5 *Discv5Service) handleNode(node *enode.Node) {
+	atomic.AddUint64(&dv5.discovered, 1)
+	nodesFound.Inc()
+	dv5.handleC <- node
+	handleQueueDepth.Set(float64(len(dv5.handleC)))
+}
+
+// logThroughput periodically reports the rate of discovered and handled nodes
+func (dv5 *Discv5Service) logThroughput() {
+	ticker := time.NewTicker(throughputInterval)
+	defer ticker.Stop()
+
+	var prevDiscovered, prevHandled uint64
+	for {
+		select {
+		case <-ticker.C:
+			discovered := atomic.LoadUint64(&dv5.discovered)
+			handled := atomic.LoadUint64(&dv5.handled)
+			log.WithFields(log.Fields{
+				"discovered":     discovered,
+				"handled":        handled,
+				"discovered/min": discovered - prevDiscovered,
+				"handled/min":    handled - prevHandled,
+				"queued":         len(dv5.handleC),
+			}).Info("discovery throughput")
+			prevDiscovered, prevHandled = discovered, handled
+		case <-dv5.closeC:
+			return
+		case <-dv5.ctx.Done():
+			return
+		}
+	}
+}
+
+// Close stops the node iterator and the targeted crawl (unblocking Run and RunTargeted),
+// and the UDPv5 listener
+func (dv5 *Discv5Service) Close() {
+	dv5.closeOnce.Do(func() {
+		close(dv5.closeC)
+		dv5.iterator.Close()
+		dv5.dv5Listener.Close()
+	})
 }
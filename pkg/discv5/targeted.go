@@ -0,0 +1,138 @@
+package discv5
+
+import (
+	"time"
+
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// range of log-distances requested to each node (the closer buckets are barely ever populated)
+	maxFindnodeDistance = 256
+	minFindnodeDistance = 239
+)
+
+// RoutingTable gathers the nodes that a peer returned for the FINDNODE requests at each distance
+type RoutingTable struct {
+	NodeID    enode.ID
+	Timestamp time.Time
+	Neighbors []*enode.Node
+	Err       error
+}
+
+// RunTargeted crawls the network by walking the k-buckets of every discovered node.
+// Starting from the given seeds, it sends FINDNODE requests at distances 256..239 to each node
+// of the frontier, handling every new node and the routing table of each peer,
+// until there are no more nodes left to visit. The routing tables of as many nodes as workers
+// are requested at the same time
+func (dv5 *Discv5Service) RunTargeted(seeds []*enode.Node, rtHandler func(*RoutingTable)) {
+	dv5.startHandlers()
+	defer dv5.stopHandlers()
+
+	visited := make(map[enode.ID]struct{})
+	frontier := make([]*enode.Node, 0, len(seeds))
+
+	addToFrontier := func(node *enode.Node) {
+		if _, ok := visited[node.ID()]; ok {
+			return
+		}
+		visited[node.ID()] = struct{}{}
+		dv5.handleNode(node)
+		frontier = append(frontier, node)
+	}
+	for _, seed := range seeds {
+		addToFrontier(seed)
+	}
+
+	jobC := make(chan *enode.Node)
+	resultC := make(chan *RoutingTable)
+	for worker := 0; worker < dv5.workers; worker++ {
+		go func() {
+			for node := range jobC {
+				rt := dv5.crawlRoutingTable(node)
+				select {
+				case resultC <- rt:
+				case <-dv5.closeC:
+					return
+				}
+			}
+		}()
+	}
+	defer close(jobC)
+
+	var inFlight, reachable, failed int
+	for len(frontier) > 0 || inFlight > 0 {
+		// only offer a job to the workers if there is something in the frontier
+		var sendC chan *enode.Node
+		var next *enode.Node
+		if len(frontier) > 0 {
+			sendC = jobC
+			next = frontier[0]
+		}
+
+		select {
+		case sendC <- next:
+			frontier = frontier[1:]
+			inFlight++
+
+		case rt := <-resultC:
+			inFlight--
+			if rt.Err != nil {
+				failed++
+			} else {
+				reachable++
+			}
+			rtHandler(rt)
+			for _, neighbor := range rt.Neighbors {
+				addToFrontier(neighbor)
+			}
+
+		case <-dv5.closeC:
+			log.Info("discv5 service closed, interrupting the targeted crawl")
+			return
+
+		case <-dv5.ctx.Done():
+			return
+		}
+	}
+
+	log.WithFields(log.Fields{
+		"visited":   len(visited),
+		"reachable": reachable,
+		"failed":    failed,
+	}).Info("targeted crawl finished, no nodes left in the frontier")
+}
+
+// crawlRoutingTable requests the content of every k-bucket of the given node
+func (dv5 *Discv5Service) crawlRoutingTable(node *enode.Node) *RoutingTable {
+	rt := &RoutingTable{
+		NodeID:    node.ID(),
+		Timestamp: time.Now(),
+		Neighbors: make([]*enode.Node, 0),
+	}
+	seen := make(map[enode.ID]struct{})
+
+	for distance := maxFindnodeDistance; distance >= minFindnodeDistance; distance-- {
+		neighbors, err := dv5.dv5Listener.Findnode(node, []uint{uint(distance)})
+		if err != nil {
+			// an unreachable node won't answer any other distance
+			if len(rt.Neighbors) == 0 {
+				rt.Err = err
+				break
+			}
+			log.Debugf("findnode at distance %d to node %s failed - %s", distance, node.ID(), err.Error())
+			continue
+		}
+		for _, neighbor := range neighbors {
+			if _, ok := seen[neighbor.ID()]; ok {
+				continue
+			}
+			seen[neighbor.ID()] = struct{}{}
+			rt.Neighbors = append(rt.Neighbors, neighbor)
+		}
+	}
+	routingTableRequests.WithLabelValues(resultLabel(rt.Err)).Inc()
+	log.Debugf("got %d neighbors from node %s", len(rt.Neighbors), node.ID())
+	return rt
+}
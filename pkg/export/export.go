@@ -0,0 +1,23 @@
+package export
+
+import (
+	"github.com/migalabs/eth-light-crawler/pkg/db"
+	"github.com/pkg/errors"
+)
+
+// Export writes the ENRs of the store that match the filter, returning the number of exported records
+func Export(store db.Store, filter *db.EnrFilter, writer Writer, anonymizeIPs bool) (int, error) {
+	enrs, err := store.GetEnrs(filter)
+	if err != nil {
+		return 0, err
+	}
+	for i, enr := range enrs {
+		if err := writer.Write(NewRecord(enr, anonymizeIPs)); err != nil {
+			return i, errors.Wrap(err, "unable to export record")
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return len(enrs), errors.Wrap(err, "unable to flush the exported records")
+	}
+	return len(enrs), nil
+}
@@ -0,0 +1,95 @@
+package export
+
+import (
+	"sync"
+
+	"github.com/migalabs/eth-light-crawler/pkg/db"
+	"github.com/migalabs/eth-light-crawler/pkg/discv5"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// FileStore streams every discovered ENR as a line of a rotating NDJSON file, so that the crawler
+// can run without any db. The latest ENR of each node (and the crawls) are also kept in memory
+// to serve the reads, while the routing tables and the ping results are dropped
+type FileStore struct {
+	*db.MemoryStore
+
+	m      sync.Mutex
+	file   *rotatingFile
+	writer Writer
+}
+
+// NewFileStore appends the ENRs to the file at path, which gets rotated once it reaches
+// maxSize bytes (0 = never)
+func NewFileStore(path string, maxSize int64) (*FileStore, error) {
+	if len(path) == 0 {
+		return nil, errors.New("empty output file path provided")
+	}
+	file, err := newRotatingFile(path, maxSize)
+	if err != nil {
+		return nil, err
+	}
+	writer, err := NewWriter(NDJSONFormat, file)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	log.WithField("path", path).Info("writing the discovered nodes to the output file")
+	return &FileStore{
+		MemoryStore: db.NewMemoryStore(),
+		file:        file,
+		writer:      writer,
+	}, nil
+}
+
+func (s *FileStore) UpsertEnr(enr *discv5.EnrNode) error {
+	if err := s.write(enr); err != nil {
+		return err
+	}
+	return s.MemoryStore.UpsertEnr(enr)
+}
+
+func (s *FileStore) UpsertForeignEnr(enr *db.ForeignEnr) error {
+	return s.write(enr.EnrNode)
+}
+
+func (s *FileStore) UpsertImportedEnr(enr *db.ImportedEnr) error {
+	if err := s.write(enr.EnrNode); err != nil {
+		return err
+	}
+	return s.MemoryStore.UpsertImportedEnr(enr)
+}
+
+// InsertEnrHistory is a no-op, since every observation of an ENR already is a line of the file
+func (s *FileStore) InsertEnrHistory(enr *db.EnrHistory) error {
+	return nil
+}
+
+func (s *FileStore) InsertRoutingTable(rt *db.RoutingTable) error {
+	return nil
+}
+
+func (s *FileStore) UpsertPingResult(result *db.PingResult) error {
+	return nil
+}
+
+func (s *FileStore) write(enr *discv5.EnrNode) error {
+	s.m.Lock()
+	defer s.m.Unlock()
+	if err := s.writer.Write(NewRecord(enr, false)); err != nil {
+		return errors.Wrap(err, "unable to write the enr to the output file")
+	}
+	return nil
+}
+
+func (s *FileStore) Close() {
+	s.m.Lock()
+	defer s.m.Unlock()
+	if err := s.writer.Close(); err != nil {
+		log.Error(err)
+	}
+	if err := s.file.Close(); err != nil {
+		log.Error(errors.Wrap(err, "unable to close the output file"))
+	}
+}
@@ -0,0 +1,105 @@
+package export
+
+import (
+	"encoding/hex"
+	"net"
+	"time"
+
+	gcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/migalabs/eth-light-crawler/pkg/discv5"
+)
+
+// IPs are anonymized by zeroing their host part: the last byte of the IPv4 (/24 network)
+// and the last 80 bits of the IPv6 (/48 network)
+var (
+	anonymizedIPv4Mask = net.CIDRMask(24, 32)
+	anonymizedIPv6Mask = net.CIDRMask(48, 128)
+)
+
+// Record is the flat representation of an ENR that gets exported
+type Record struct {
+	NodeID          string    `json:"node_id" parquet:"node_id"`
+	Seq             uint64    `json:"seq" parquet:"seq"`
+	Timestamp       time.Time `json:"timestamp" parquet:"timestamp,timestamp(millisecond)"`
+	IP              string    `json:"ip" parquet:"ip,optional"`
+	TCP             int       `json:"tcp" parquet:"tcp"`
+	UDP             int       `json:"udp" parquet:"udp"`
+	IP6             string    `json:"ip6" parquet:"ip6,optional"`
+	TCP6            int       `json:"tcp6" parquet:"tcp6"`
+	UDP6            int       `json:"udp6" parquet:"udp6"`
+	Pubkey          string    `json:"pubkey" parquet:"pubkey"`
+	ForkDigest      string    `json:"fork_digest" parquet:"fork_digest"`
+	NextForkVersion string    `json:"next_fork_version" parquet:"next_fork_version"`
+	NextForkEpoch   uint64    `json:"next_fork_epoch" parquet:"next_fork_epoch"`
+	Attnets         string    `json:"attnets" parquet:"attnets"`
+	AttnetsNumber   int       `json:"attnets_number" parquet:"attnets_number"`
+	Syncnets        string    `json:"syncnets" parquet:"syncnets"`
+	SyncnetsNumber  int       `json:"syncnets_number" parquet:"syncnets_number"`
+	// raw record, left empty if the IPs are anonymized (the signed record contains them)
+	Enr string `json:"enr" parquet:"enr,optional"`
+}
+
+// csvHeader are the column names of the CSV export, in the order of the Record fields
+var csvHeader = []string{
+	"node_id",
+	"seq",
+	"timestamp",
+	"ip",
+	"tcp",
+	"udp",
+	"ip6",
+	"tcp6",
+	"udp6",
+	"pubkey",
+	"fork_digest",
+	"next_fork_version",
+	"next_fork_epoch",
+	"attnets",
+	"attnets_number",
+	"syncnets",
+	"syncnets_number",
+	"enr",
+}
+
+// NewRecord flattens the ENR, anonymizing its IPs if requested
+func NewRecord(enr *discv5.EnrNode, anonymizeIPs bool) *Record {
+	record := &Record{
+		NodeID:          enr.ID.String(),
+		Seq:             enr.Seq,
+		Timestamp:       enr.Timestamp.UTC(),
+		IP:              ipString(enr.IP, anonymizeIPs),
+		TCP:             enr.TCP,
+		UDP:             enr.UDP,
+		IP6:             ipString(enr.IP6, anonymizeIPs),
+		TCP6:            enr.TCP6,
+		UDP6:            enr.UDP6,
+		ForkDigest:      enr.Eth2Data.ForkDigest.String(),
+		NextForkVersion: enr.Eth2Data.NextForkVersion.String(),
+		NextForkEpoch:   uint64(enr.Eth2Data.NextForkEpoch),
+		Attnets:         hex.EncodeToString(enr.Attnets.Raw[:]),
+		AttnetsNumber:   enr.Attnets.NetNumber,
+		Syncnets:        hex.EncodeToString(enr.Syncnets.Raw[:]),
+		SyncnetsNumber:  enr.Syncnets.NetNumber,
+	}
+	if enr.Pubkey != nil && enr.Pubkey.X != nil {
+		record.Pubkey = hex.EncodeToString(gcrypto.CompressPubkey(enr.Pubkey))
+	}
+	if !anonymizeIPs {
+		record.Enr = enr.Enr
+	}
+	return record
+}
+
+// ipString returns the (anonymized) IP, or the empty string if there is none
+func ipString(ip net.IP, anonymize bool) string {
+	if len(ip) == 0 {
+		return ""
+	}
+	if !anonymize {
+		return ip.String()
+	}
+	if ip4 := ip.To4(); ip4 != nil {
+		return ip4.Mask(anonymizedIPv4Mask).String()
+	}
+	return ip.Mask(anonymizedIPv6Mask).String()
+}
@@ -0,0 +1,97 @@
+package export
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// rotatingFile appends to the file at path, moving it aside to <name>-<timestamp><ext>
+// once it reaches the max size, so that long crawls don't produce a single huge file
+type rotatingFile struct {
+	m       sync.Mutex
+	path    string
+	maxSize int64
+	file    *os.File
+	size    int64
+}
+
+func newRotatingFile(path string, maxSize int64) (*rotatingFile, error) {
+	r := &rotatingFile{
+		path:    path,
+		maxSize: maxSize,
+	}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// open opens (or creates) the file at path, appending to its content
+func (r *rotatingFile) open() error {
+	file, err := os.OpenFile(r.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return errors.Wrap(err, "unable to open the output file")
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return errors.Wrap(err, "unable to read the size of the output file")
+	}
+	r.file = file
+	r.size = info.Size()
+	return nil
+}
+
+// Write appends p to the file, rotating it first if p doesn't fit in it
+// (p is never split, so lines written at once stay in the same file)
+func (r *rotatingFile) Write(p []byte) (int, error) {
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	if r.maxSize > 0 && r.size > 0 && r.size+int64(len(p)) > r.maxSize {
+		if err := r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+func (r *rotatingFile) rotate() error {
+	if err := r.file.Close(); err != nil {
+		return errors.Wrap(err, "unable to close the output file")
+	}
+	rotated := rotatedPath(r.path, time.Now())
+	if err := os.Rename(r.path, rotated); err != nil {
+		return errors.Wrap(err, "unable to rotate the output file")
+	}
+	log.Infof("rotated the output file to %s", rotated)
+	return r.open()
+}
+
+// rotatedPath returns a free <name>-<timestamp>[.n]<ext> path next to the given one
+func rotatedPath(path string, t time.Time) string {
+	ext := filepath.Ext(path)
+	base := strings.TrimSuffix(path, ext) + "-" + t.UTC().Format("20060102T150405")
+	rotated := base + ext
+	for i := 1; ; i++ {
+		if _, err := os.Stat(rotated); os.IsNotExist(err) {
+			return rotated
+		}
+		rotated = fmt.Sprintf("%s.%d%s", base, i, ext)
+	}
+}
+
+func (r *rotatingFile) Close() error {
+	r.m.Lock()
+	defer r.m.Unlock()
+	return r.file.Close()
+}
@@ -0,0 +1,131 @@
+package export
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestRotatedPath(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2023, 5, 1, 12, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		path     string
+		existing []string
+		expected string
+	}{
+		{
+			name:     "free path",
+			path:     "enrs.ndjson",
+			expected: "enrs-20230501T123000.ndjson",
+		},
+		{
+			name:     "no extension",
+			path:     "enrs",
+			expected: "enrs-20230501T123000",
+		},
+		{
+			name:     "taken paths",
+			path:     "nodes.ndjson",
+			existing: []string{"nodes-20230501T123000.ndjson", "nodes-20230501T123000.1.ndjson"},
+			expected: "nodes-20230501T123000.2.ndjson",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for _, name := range test.existing {
+				if err := os.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			rotated := rotatedPath(filepath.Join(dir, test.path), now)
+			if rotated != filepath.Join(dir, test.expected) {
+				t.Fatalf("rotated to %s, expected %s", rotated, test.expected)
+			}
+		})
+	}
+}
+
+func TestRotatingFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		maxSize int64
+		writes  []string
+		files   []string
+	}{
+		{
+			name:    "no max size",
+			maxSize: 0,
+			writes:  []string{"aaaa\n", "bbbb\n", "cccc\n"},
+			files:   []string{"aaaa\nbbbb\ncccc\n"},
+		},
+		{
+			name:    "rotates when full",
+			maxSize: 10,
+			writes:  []string{"aaaa\n", "bbbb\n", "cccc\n"},
+			files:   []string{"aaaa\nbbbb\n", "cccc\n"},
+		},
+		{
+			name:    "never splits a write",
+			maxSize: 4,
+			writes:  []string{"aaaaaa\n", "bb\n", "cc\n"},
+			files:   []string{"aaaaaa\n", "bb\n", "cc\n"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "enrs.ndjson")
+			file, err := newRotatingFile(path, test.maxSize)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, write := range test.writes {
+				if _, err := file.Write([]byte(write)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := file.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			// the rotated files come first (by name), the current one last
+			rotated, err := filepath.Glob(filepath.Join(dir, "enrs-*.ndjson"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			sort.Slice(rotated, func(i, j int) bool {
+				return rotationIndex(rotated[i]) < rotationIndex(rotated[j])
+			})
+			paths := append(rotated, path)
+			if len(paths) != len(test.files) {
+				t.Fatalf("wrote %d files, expected %d", len(paths), len(test.files))
+			}
+			for i, p := range paths {
+				content, err := os.ReadFile(p)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if string(content) != test.files[i] {
+					t.Errorf("file %s has %q, expected %q", filepath.Base(p), content, test.files[i])
+				}
+			}
+		})
+	}
+}
+
+// rotationIndex orders the rotated files of the same second (<name>-<timestamp>[.n]<ext>)
+func rotationIndex(path string) string {
+	name := filepath.Base(path)
+	// pad the unnumbered one, so that it sorts before <name>-<timestamp>.1<ext>
+	if len(name) == len("enrs-20060102T150405.ndjson") {
+		return name[:len(name)-len(".ndjson")] + ".0"
+	}
+	return name
+}
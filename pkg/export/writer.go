@@ -0,0 +1,105 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/pkg/errors"
+)
+
+// supported export formats
+const (
+	CSVFormat     = "csv"
+	NDJSONFormat  = "ndjson"
+	ParquetFormat = "parquet"
+)
+
+// Writer encodes the exported records in one of the supported formats
+type Writer interface {
+	Write(record *Record) error
+	// Close flushes the buffered records (it doesn't close the underlying io.Writer)
+	Close() error
+}
+
+// NewWriter returns the writer of the given format over w
+func NewWriter(format string, w io.Writer) (Writer, error) {
+	switch format {
+	case CSVFormat:
+		return newCSVWriter(w)
+	case NDJSONFormat:
+		return &ndjsonWriter{encoder: json.NewEncoder(w)}, nil
+	case ParquetFormat:
+		return &parquetWriter{writer: parquet.NewGenericWriter[Record](w)}, nil
+	default:
+		return nil, errors.Errorf("unknown export format %s, expected %s, %s or %s", format, CSVFormat, NDJSONFormat, ParquetFormat)
+	}
+}
+
+type csvWriter struct {
+	writer *csv.Writer
+}
+
+func newCSVWriter(w io.Writer) (*csvWriter, error) {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvHeader); err != nil {
+		return nil, errors.Wrap(err, "unable to write the csv header")
+	}
+	return &csvWriter{writer: writer}, nil
+}
+
+func (c *csvWriter) Write(record *Record) error {
+	return c.writer.Write([]string{
+		record.NodeID,
+		strconv.FormatUint(record.Seq, 10),
+		record.Timestamp.Format(time.RFC3339),
+		record.IP,
+		strconv.Itoa(record.TCP),
+		strconv.Itoa(record.UDP),
+		record.IP6,
+		strconv.Itoa(record.TCP6),
+		strconv.Itoa(record.UDP6),
+		record.Pubkey,
+		record.ForkDigest,
+		record.NextForkVersion,
+		strconv.FormatUint(record.NextForkEpoch, 10),
+		record.Attnets,
+		strconv.Itoa(record.AttnetsNumber),
+		record.Syncnets,
+		strconv.Itoa(record.SyncnetsNumber),
+		record.Enr,
+	})
+}
+
+func (c *csvWriter) Close() error {
+	c.writer.Flush()
+	return c.writer.Error()
+}
+
+type ndjsonWriter struct {
+	encoder *json.Encoder
+}
+
+func (n *ndjsonWriter) Write(record *Record) error {
+	return n.encoder.Encode(record)
+}
+
+func (n *ndjsonWriter) Close() error {
+	return nil
+}
+
+type parquetWriter struct {
+	writer *parquet.GenericWriter[Record]
+}
+
+func (p *parquetWriter) Write(record *Record) error {
+	_, err := p.writer.Write([]Record{*record})
+	return err
+}
+
+func (p *parquetWriter) Close() error {
+	return p.writer.Close()
+}
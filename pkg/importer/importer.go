@@ -0,0 +1,107 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+
+	"github.com/migalabs/eth-light-crawler/pkg/db"
+	"github.com/migalabs/eth-light-crawler/pkg/discv5"
+	"github.com/migalabs/eth-light-crawler/pkg/utils"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+const enrPrefix = "enr:"
+
+// ReadRecords extracts the ENR strings of the input, which can be either a list of records
+// (one per line, skipping the empty and the # comment lines), or JSON: any string starting with
+// "enr:" within the JSON values is read as a record, so that arrays of ENRs, the identity of the
+// beacon API (/eth/v1/node/identity) or the NDJSON exports of the crawler can be imported as they are
+func ReadRecords(r io.Reader) ([]string, error) {
+	input, err := io.ReadAll(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to read the input")
+	}
+	trimmed := bytes.TrimSpace(input)
+	if len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[') {
+		return readJSONRecords(trimmed)
+	}
+	records, err := utils.ReadRecordLines(bytes.NewReader(trimmed))
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to read the input lines")
+	}
+	return records, nil
+}
+
+// readJSONRecords reads a stream of JSON values (i.e. a single document or NDJSON)
+func readJSONRecords(input []byte) ([]string, error) {
+	records := make([]string, 0)
+	decoder := json.NewDecoder(bytes.NewReader(input))
+	for {
+		var value interface{}
+		err := decoder.Decode(&value)
+		if err == io.EOF {
+			return records, nil
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to decode the json input")
+		}
+		records = collectRecords(value, records)
+	}
+}
+
+// collectRecords appends the ENR strings found within the JSON value
+func collectRecords(value interface{}, records []string) []string {
+	switch v := value.(type) {
+	case string:
+		if strings.HasPrefix(v, enrPrefix) {
+			records = append(records, v)
+		}
+	case []interface{}:
+		for _, item := range v {
+			records = collectRecords(item, records)
+		}
+	case map[string]interface{}:
+		for _, item := range v {
+			records = collectRecords(item, records)
+		}
+	}
+	return records
+}
+
+// ImportStats counts the outcome of an import
+type ImportStats struct {
+	Imported   int
+	Duplicated int
+	Invalid    int
+}
+
+// Import parses the records as the ENR handler of the crawler does, and upserts them into the store
+// tagged with the given source. It returns the parsed nodes, so that they can seed a crawl
+func Import(store db.Store, records []string, source string) ([]*discv5.EnrNode, *ImportStats, error) {
+	stats := new(ImportStats)
+	enrs := make([]*discv5.EnrNode, 0, len(records))
+	seen := make(map[string]struct{}, len(records))
+	for _, record := range records {
+		if _, ok := seen[record]; ok {
+			stats.Duplicated++
+			continue
+		}
+		seen[record] = struct{}{}
+
+		enr, err := discv5.ParseEnr(record)
+		if err != nil {
+			log.Warnf("skipping invalid record %s - %s", record, err.Error())
+			stats.Invalid++
+			continue
+		}
+		if err := store.UpsertImportedEnr(&db.ImportedEnr{EnrNode: enr, Source: source}); err != nil {
+			return enrs, stats, err
+		}
+		enrs = append(enrs, enr)
+		stats.Imported++
+	}
+	return enrs, stats, nil
+}
@@ -0,0 +1,84 @@
+package importer
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestReadRecords(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		records []string
+		valid   bool
+	}{
+		{
+			name:    "empty",
+			input:   "",
+			records: []string{},
+			valid:   true,
+		},
+		{
+			name:    "lines",
+			input:   "enr:-a\n\n  enr:-b  \n",
+			records: []string{"enr:-a", "enr:-b"},
+			valid:   true,
+		},
+		{
+			name:    "comments",
+			input:   "# mainnet bootnodes\nenr:-a # teku\n#enr:-b\n",
+			records: []string{"enr:-a"},
+			valid:   true,
+		},
+		{
+			name:    "quoted YAML list",
+			input:   "# bootnodes\n- \"enr:-a\" # lighthouse\n- 'enr:-b'\n- enr:-c\n",
+			records: []string{"enr:-a", "enr:-b", "enr:-c"},
+			valid:   true,
+		},
+		{
+			name:    "JSON array",
+			input:   `["enr:-a", "enr:-b", "not an enr", 3]`,
+			records: []string{"enr:-a", "enr:-b"},
+			valid:   true,
+		},
+		{
+			name:    "beacon API identity",
+			input:   `{"data": {"peer_id": "16Uiu2", "enr": "enr:-a", "p2p_addresses": ["/ip4/10.0.0.1/tcp/9000"]}}`,
+			records: []string{"enr:-a"},
+			valid:   true,
+		},
+		{
+			name:    "NDJSON export",
+			input:   "{\"node_id\": \"0x01\", \"enr\": \"enr:-a\"}\n{\"node_id\": \"0x02\", \"enr\": \"enr:-b\"}\n",
+			records: []string{"enr:-a", "enr:-b"},
+			valid:   true,
+		},
+		{
+			name:  "invalid JSON",
+			input: `["enr:-a",`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			records, err := ReadRecords(strings.NewReader(test.input))
+			if !test.valid {
+				if err == nil {
+					t.Fatalf("expected an error, got %v", records)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			// the records of the JSON objects come in no particular order
+			sort.Strings(records)
+			if !reflect.DeepEqual(records, test.records) {
+				t.Fatalf("read %q, expected %q", records, test.records)
+			}
+		})
+	}
+}
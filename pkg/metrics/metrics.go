@@ -0,0 +1,41 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	log "github.com/sirupsen/logrus"
+)
+
+// Namespace prefixes the metrics exported by every module of the crawler
+const Namespace = "eth_light_crawler"
+
+const shutdownTimeout = 5 * time.Second
+
+// Serve exposes the registered metrics at http://<addr>/metrics until the context is done
+func Serve(ctx context.Context, addr string) error {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: shutdownTimeout,
+	}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+
+	log.Infof("serving metrics at http://%s/metrics", addr)
+	err := server.ListenAndServe()
+	if err != nil && err != http.ErrServerClosed {
+		return errors.Wrap(err, "unable to serve the metrics")
+	}
+	return nil
+}
@@ -1,12 +1,72 @@
 package utils
 
 import (
+	"bufio"
 	"crypto/ecdsa"
 	"crypto/rand"
+	"io"
+	"os"
+	"strings"
 
 	gcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
 )
 
 func GenNewPrivKey() (*ecdsa.PrivateKey, error) {
 	return ecdsa.GenerateKey(gcrypto.S256(), rand.Reader)
 }
+
+// ParsePrivKey decodes a hex encoded secp256k1 private key
+func ParsePrivKey(hexKey string) (*ecdsa.PrivateKey, error) {
+	privK, err := gcrypto.HexToECDSA(strings.TrimPrefix(hexKey, "0x"))
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to parse hex privkey")
+	}
+	return privK, nil
+}
+
+// LoadOrGenPrivKey reads the hex encoded secp256k1 private key stored at the given path,
+// generating and saving a new one if the file doesn't exist yet
+func LoadOrGenPrivKey(path string) (*ecdsa.PrivateKey, error) {
+	privK, err := gcrypto.LoadECDSA(path)
+	if err == nil {
+		return privK, nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, errors.Wrap(err, "unable to read privkey from "+path)
+	}
+
+	privK, err = GenNewPrivKey()
+	if err != nil {
+		return nil, errors.Wrap(err, "error generating privkey")
+	}
+	err = gcrypto.SaveECDSA(path, privK)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to save privkey at "+path)
+	}
+	return privK, nil
+}
+
+// ReadRecordLines reads the node records (ENRs or enode URLs) listed one per line, skipping the empty
+// and the # comment lines. Since bootnode lists often quote and comment the records (i.e. YAML lists),
+// the "- " list markers, the quotes and the trailing " #" comments are left out of the records
+func ReadRecordLines(r io.Reader) ([]string, error) {
+	records := make([]string, 0)
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if i := strings.Index(line, " #"); i >= 0 {
+			line = line[:i]
+		}
+		line = strings.Trim(strings.TrimPrefix(line, "- "), `"' `)
+		records = append(records, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return records, nil
+}